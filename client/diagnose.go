@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tikv/client-go/v2/config"
+)
+
+// EndpointStatus 单个 PD 地址的连通性检查结果
+type EndpointStatus struct {
+	Endpoint string
+	OK       bool
+	Problem  string // 失败原因的简要分类，如 connection refused、timeout、TLS handshake failed
+	Err      error
+}
+
+func (s EndpointStatus) String() string {
+	if s.OK {
+		return fmt.Sprintf("%s: reachable", s.Endpoint)
+	}
+	if s.Err != nil {
+		return fmt.Sprintf("%s: %s (%v)", s.Endpoint, s.Problem, s.Err)
+	}
+	return fmt.Sprintf("%s: %s", s.Endpoint, s.Problem)
+}
+
+// ConnectError 连接集群失败时返回的错误，附带每个 PD 地址的诊断信息
+type ConnectError struct {
+	Err      error
+	Statuses []EndpointStatus
+}
+
+func (e *ConnectError) Error() string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("failed to connect to PD: %v", e.Err))
+	for _, status := range e.Statuses {
+		s.WriteString("\n  - " + status.String())
+	}
+	return s.String()
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// DiagnoseEndpoints 并发检查每个 PD 地址的 TCP 连通性，配置了 TLS 时同时检查 TLS 握手
+func DiagnoseEndpoints(ctx context.Context, endpoints []string, security *config.Security, timeout time.Duration) []EndpointStatus {
+	var tlsCfg *tls.Config
+	var tlsErr error
+	if security != nil {
+		tlsCfg, tlsErr = security.ToTLSConfig()
+	}
+
+	statuses := make([]EndpointStatus, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			statuses[i] = diagnoseEndpoint(ctx, endpoint, tlsCfg, tlsErr, timeout)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func diagnoseEndpoint(ctx context.Context, endpoint string, tlsCfg *tls.Config, tlsErr error, timeout time.Duration) EndpointStatus {
+	status := EndpointStatus{Endpoint: endpoint}
+
+	// PD 地址可能带有 http:// 或 https:// 前缀
+	addr := strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	addr = strings.TrimSuffix(addr, "/")
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		status.Problem = classifyDialError(err)
+		status.Err = err
+		return status
+	}
+	defer conn.Close()
+
+	if tlsErr != nil {
+		status.Problem = "invalid TLS config"
+		status.Err = tlsErr
+		return status
+	}
+
+	if tlsCfg != nil {
+		cfg := tlsCfg.Clone()
+		if cfg.ServerName == "" {
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				cfg.ServerName = host
+			}
+		}
+
+		tlsConn := tls.Client(conn, cfg)
+		_ = tlsConn.SetDeadline(time.Now().Add(timeout))
+		if err := tlsConn.Handshake(); err != nil {
+			status.Problem = "TLS handshake failed"
+			status.Err = err
+			return status
+		}
+	}
+
+	status.OK = true
+	return status
+}
+
+// classifyDialError 将拨号错误归类为便于排查的原因
+func classifyDialError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return "DNS lookup failed"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return "host unreachable"
+	default:
+		return "dial failed"
+	}
+}
@@ -2,74 +2,220 @@ package client
 
 import (
 	"context"
-	"log"
-	"sync"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pkg/errors"
 	"github.com/tikv/client-go/v2/config"
 	"github.com/tikv/client-go/v2/rawkv"
+	pd "github.com/tikv/pd/client"
 	"google.golang.org/grpc"
 )
 
-var (
-	once        sync.Once
-	RawKVClient *rawkv.Client
-)
-
+// RawKvClient 持有一个到 TiKV 集群的 rawkv 连接，使用完毕后需要调用 Close
 type RawKvClient struct {
+	cli *rawkv.Client
+
+	// 创建连接时使用的参数，切换 keyspace 时复用
+	endpoints []string
+	opts      []CliOpt
 }
 
 func NewRawKvClient(ctx context.Context, endpoints []string, opts ...CliOpt) (*RawKvClient, error) {
-	client := &RawKvClient{}
+	cli, err := newClient(ctx, endpoints, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
-	once.Do(func() {
-		// 处理选项
-		option := &option{}
-		for _, opt := range opts {
-			opt(option)
-		}
+	return &RawKvClient{
+		cli:       cli,
+		endpoints: endpoints,
+		opts:      opts,
+	}, nil
+}
+
+// WrapRawClient 包装一个已经创建好的 rawkv 客户端，例如测试中连接 mock 集群的客户端。
+// 包装得到的客户端不知道连接参数，不能使用 WithKeyspace
+func WrapRawClient(cli *rawkv.Client) *RawKvClient {
+	return &RawKvClient{cli: cli}
+}
 
-		// 构建 rawkv 客户端选项
-		rawkvOpts := []rawkv.ClientOpt{}
+// Raw 返回底层的 rawkv 客户端
+func (c *RawKvClient) Raw() *rawkv.Client {
+	return c.cli
+}
 
-		// 如果指定了 API V2，则使用 V2
-		if option.apiVersionV2 {
-			rawkvOpts = append(rawkvOpts, rawkv.WithAPIVersion(kvrpcpb.APIVersion_V2))
-		}
+// WithKeyspace 使用相同的连接参数创建一个连接到指定 keyspace 的新客户端，
+// 原客户端不受影响，需要调用方自行关闭
+func (c *RawKvClient) WithKeyspace(ctx context.Context, keyspace string) (*RawKvClient, error) {
+	if len(c.endpoints) == 0 {
+		return nil, errors.New("client was not created from PD endpoints, cannot switch keyspace")
+	}
+	opts := append(append([]CliOpt{}, c.opts...), WithKeyspace(keyspace))
+	cli, err := newClient(ctx, c.endpoints, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-		if option.tlsCfg != nil {
-			rawkvOpts = append(rawkvOpts, rawkv.WithSecurity(*option.tlsCfg))
-		}
+	return &RawKvClient{
+		cli:       cli,
+		endpoints: c.endpoints,
+		opts:      opts,
+	}, nil
+}
+
+// Close 关闭到集群的连接
+func (c *RawKvClient) Close() error {
+	if c == nil || c.cli == nil {
+		return nil
+	}
+	return c.cli.Close()
+}
+
+func newClient(ctx context.Context, endpoints []string, opts ...CliOpt) (*rawkv.Client, error) {
+	// 处理选项
+	option := &option{}
+	for _, opt := range opts {
+		opt(option)
+	}
 
-		if option.grpcOpts != nil {
-			rawkvOpts = append(rawkvOpts, rawkv.WithGRPCDialOptions(option.grpcOpts...))
+	// 构建 rawkv 客户端选项
+	rawkvOpts := []rawkv.ClientOpt{}
+
+	// 指定 API 版本，未指定时使用 V1
+	rawkvOpts = append(rawkvOpts, rawkv.WithAPIVersion(option.apiVersion))
+
+	// keyspace 仅在 API V2 下生效
+	if option.keyspace != "" {
+		if option.apiVersion != kvrpcpb.APIVersion_V2 {
+			return nil, errors.Errorf("keyspace %q requires API V2", option.keyspace)
 		}
+		rawkvOpts = append(rawkvOpts, rawkv.WithKeyspace(option.keyspace))
+	}
+
+	if option.tlsCfg != nil {
+		rawkvOpts = append(rawkvOpts, rawkv.WithSecurity(*option.tlsCfg))
+	}
+
+	if option.grpcOpts != nil {
+		rawkvOpts = append(rawkvOpts, rawkv.WithGRPCDialOptions(option.grpcOpts...))
+	}
+
+	timeout := option.connectTimeout
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+	rawkvOpts = append(rawkvOpts, rawkv.WithPDOptions(pd.WithCustomTimeoutOption(timeout)))
+
+	// PD 不可达时 NewClientWithOpts 会长时间重试，这里在后台创建并限制等待时间
+	type result struct {
+		cli *rawkv.Client
+		err error
+	}
+	done := make(chan result, 1)
+	dial := option.dialer
+	if dial == nil {
+		dial = dialRawKv
+	}
+	cfg := DialConfig{APIVersion: option.apiVersion, Keyspace: option.keyspace, Options: rawkvOpts}
+	go func() {
+		cli, err := dial(ctx, endpoints, cfg)
+		done <- result{cli: cli, err: err}
+	}()
 
-		// 使用 WithOpts 创建客户端
-		RawKVClient, err = rawkv.NewClientWithOpts(ctx, endpoints, rawkvOpts...)
-		if err != nil {
-			log.Fatalln("rawkv.NewClientWithOpts: ", err.Error())
-			err = errors.Wrapf(err, "NewClientWithOpts rawkv")
-			return
+	var err error
+	select {
+	case r := <-done:
+		if r.err == nil {
+			return r.cli, nil
 		}
-	})
+		err = errors.Wrapf(r.err, "NewClientWithOpts rawkv")
+	case <-time.After(timeout):
+		err = errors.Errorf("timed out after %v", timeout)
+		// 超时后创建成功的客户端直接关闭
+		go func() {
+			if r := <-done; r.cli != nil {
+				r.cli.Close()
+			}
+		}()
+	case <-ctx.Done():
+		err = ctx.Err()
+		go func() {
+			if r := <-done; r.cli != nil {
+				r.cli.Close()
+			}
+		}()
+	}
+
+	// 连接失败，检查每个 PD 地址以给出具体原因。调用方的 ctx 可能已经取消，诊断使用独立的超时
+	diagTimeout := diagnoseTimeout(timeout)
+	diagCtx, cancel := context.WithTimeout(context.Background(), diagTimeout)
+	defer cancel()
+	return nil, &ConnectError{
+		Err:      err,
+		Statuses: DiagnoseEndpoints(diagCtx, endpoints, option.tlsCfg, diagTimeout),
+	}
+}
+
+// DialConfig 创建底层 rawkv 客户端时使用的参数，由 CliOpt 解析得到
+type DialConfig struct {
+	APIVersion kvrpcpb.APIVersion
+	Keyspace   string            // 为空时使用默认 keyspace
+	Options    []rawkv.ClientOpt // 传给 rawkv.NewClientWithOpts 的完整选项
+}
+
+// Dialer 按 PD 地址和连接参数创建底层的 rawkv 客户端
+type Dialer func(ctx context.Context, endpoints []string, cfg DialConfig) (*rawkv.Client, error)
 
-	return client, nil
+// dialRawKv 默认的 Dialer，通过 PD 连接真实集群
+func dialRawKv(ctx context.Context, endpoints []string, cfg DialConfig) (*rawkv.Client, error) {
+	return rawkv.NewClientWithOpts(ctx, endpoints, cfg.Options...)
 }
 
+// diagnoseTimeout 诊断单个地址时使用的超时时间
+func diagnoseTimeout(timeout time.Duration) time.Duration {
+	if timeout > 3*time.Second {
+		return 3 * time.Second
+	}
+	return timeout
+}
+
+// DefaultConnectTimeout 未指定时连接集群的超时时间
+const DefaultConnectTimeout = 10 * time.Second
+
 type option struct {
-	apiVersionV2 bool
-	tlsCfg       *config.Security
-	grpcOpts     []grpc.DialOption
+	apiVersion     kvrpcpb.APIVersion
+	keyspace       string
+	tlsCfg         *config.Security
+	grpcOpts       []grpc.DialOption
+	connectTimeout time.Duration
+	dialer         Dialer
 }
 
 type CliOpt func(*option)
 
+func WithApiVersionV1() CliOpt {
+	return func(o *option) {
+		o.apiVersion = kvrpcpb.APIVersion_V1
+	}
+}
+
+func WithApiVersionV1TTL() CliOpt {
+	return func(o *option) {
+		o.apiVersion = kvrpcpb.APIVersion_V1TTL
+	}
+}
+
 func WithApiVersionV2() CliOpt {
 	return func(o *option) {
-		o.apiVersionV2 = true
+		o.apiVersion = kvrpcpb.APIVersion_V2
+	}
+}
+
+// WithKeyspace 指定 API V2 下使用的 keyspace，为空时使用默认 keyspace
+func WithKeyspace(keyspace string) CliOpt {
+	return func(o *option) {
+		o.keyspace = keyspace
 	}
 }
 
@@ -79,8 +225,23 @@ func WithTls(cfg *config.Security) CliOpt {
 	}
 }
 
+// WithConnectTimeout 设置连接集群的超时时间
+func WithConnectTimeout(timeout time.Duration) CliOpt {
+	return func(o *option) {
+		o.connectTimeout = timeout
+	}
+}
+
 func WithGRPCDialOptions(opts ...grpc.DialOption) CliOpt {
 	return func(o *option) {
 		o.grpcOpts = opts
 	}
 }
+
+// WithDialer 替换创建底层 rawkv 客户端的方式，测试中用于连接进程内的 mock 集群。
+// 连接超时、参数检查和切换 keyspace 与连接真实集群时相同
+func WithDialer(dial Dialer) CliOpt {
+	return func(o *option) {
+		o.dialer = dial
+	}
+}
@@ -0,0 +1,159 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baixiaoshi/tikvtool/client"
+	"github.com/baixiaoshi/tikvtool/client/mockstore"
+
+	"github.com/tikv/client-go/v2/rawkv"
+)
+
+func TestKeyspaceRequiresAPIV2(t *testing.T) {
+	// 参数错误时在连接 PD 之前返回，不需要真实集群
+	for _, opt := range []client.CliOpt{client.WithApiVersionV1(), client.WithApiVersionV1TTL()} {
+		_, err := client.NewRawKvClient(context.Background(), []string{"127.0.0.1:1"}, opt, client.WithKeyspace("ks"))
+		if err == nil || !strings.Contains(err.Error(), "requires API V2") {
+			t.Fatalf("NewRawKvClient with a keyspace and API V1 = %v, want an API V2 error", err)
+		}
+	}
+}
+
+func TestDiagnoseAfterContextCancelled(t *testing.T) {
+	// 接受 TCP 连接但不响应的 PD 地址，连接会一直等到 ctx 取消
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = client.NewRawKvClient(ctx, []string{ln.Addr().String()}, client.WithConnectTimeout(5*time.Second))
+
+	var connectErr *client.ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("NewRawKvClient = %v, want a ConnectError", err)
+	}
+	// 诊断不能使用已经取消的 ctx，否则可以连通的地址也会报告失败
+	if len(connectErr.Statuses) != 1 || !connectErr.Statuses[0].OK {
+		t.Fatalf("diagnosis after the context was cancelled = %v, want the endpoint reachable", connectErr.Statuses)
+	}
+}
+
+func TestWrappedClientCannotSwitchKeyspace(t *testing.T) {
+	cluster := mockstore.NewCluster(t)
+	cluster.AddKeyspace(1, "users")
+	cli := cluster.NewV2Client(t, "users")
+
+	if _, err := cli.WithKeyspace(context.Background(), "orders"); err == nil {
+		t.Fatal("WithKeyspace on a wrapped client should fail")
+	}
+}
+
+func TestMockClientRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cluster := mockstore.NewCluster(t, []byte("m"))
+	cli := cluster.NewV1Client(t)
+
+	raw := cli.Raw()
+	if err := raw.Put(ctx, []byte("k"), []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := raw.Get(ctx, []byte("k")); err != nil || string(v) != "v" {
+		t.Fatalf("Get(k) = %q, %v", v, err)
+	}
+
+	// 关闭一个客户端不影响共享同一个 mock 集群的其他客户端
+	if err := cli.Close(); err != nil {
+		t.Fatal(err)
+	}
+	other := cluster.NewV1Client(t).Raw()
+	if v, err := other.Get(ctx, []byte("k")); err != nil || string(v) != "v" {
+		t.Fatalf("Get(k) from another client = %q, %v", v, err)
+	}
+}
+
+func TestNewRawKvClientWithMockCluster(t *testing.T) {
+	ctx := context.Background()
+	cluster := mockstore.NewCluster(t)
+	cluster.AddKeyspace(1, "users")
+	cluster.AddKeyspace(2, "orders")
+
+	// 通过真实的构造函数连接 mock 集群，API 版本和 keyspace 参数按正常流程生效
+	cli, err := client.NewRawKvClient(ctx, []string{"mock-pd:2379"},
+		client.WithApiVersionV2(), client.WithKeyspace("users"), client.WithDialer(cluster.Dialer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if err := cli.Raw().Put(ctx, []byte("k"), []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := cluster.NewV2Client(t, "users").Raw().Get(ctx, []byte("k")); err != nil || string(v) != "v" {
+		t.Fatalf("Get(k) in keyspace users = %q, %v", v, err)
+	}
+
+	// 切换 keyspace 复用相同的连接参数，包括 Dialer
+	orders, err := cli.WithKeyspace(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orders.Close()
+	if v, err := orders.Raw().Get(ctx, []byte("k")); err != nil || v != nil {
+		t.Fatalf("Get(k) in keyspace orders = %q, %v, want keyspaces isolated", v, err)
+	}
+
+	v1, err := client.NewRawKvClient(ctx, []string{"mock-pd:2379"}, client.WithApiVersionV1(), client.WithDialer(cluster.Dialer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v1.Close()
+	if v, err := v1.Raw().Get(ctx, []byte("k")); err != nil || v != nil {
+		t.Fatalf("Get(k) with API V1 = %q, %v, want API V2 keys to be encoded", v, err)
+	}
+}
+
+func TestNewRawKvClientUnknownKeyspace(t *testing.T) {
+	cluster := mockstore.NewCluster(t)
+	_, err := client.NewRawKvClient(context.Background(), []string{"127.0.0.1:1"},
+		client.WithApiVersionV2(), client.WithKeyspace("missing"), client.WithDialer(cluster.Dialer()))
+
+	var connectErr *client.ConnectError
+	if !errors.As(err, &connectErr) || !strings.Contains(err.Error(), "keyspace missing not found") {
+		t.Fatalf("NewRawKvClient with an unknown keyspace = %v", err)
+	}
+}
+
+func TestNewRawKvClientConnectTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	blocking := func(ctx context.Context, endpoints []string, cfg client.DialConfig) (*rawkv.Client, error) {
+		<-release
+		return nil, errors.New("released")
+	}
+
+	start := time.Now()
+	_, err := client.NewRawKvClient(context.Background(), []string{"127.0.0.1:1"},
+		client.WithConnectTimeout(100*time.Millisecond), client.WithDialer(blocking))
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Fatalf("NewRawKvClient with a hanging dial = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("NewRawKvClient returned after %v, want the connect timeout to apply", elapsed)
+	}
+}
@@ -5,13 +5,96 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/baixiaoshi/tikvtool/client"
+	"github.com/baixiaoshi/tikvtool/ui"
+
+	tikvconfig "github.com/tikv/client-go/v2/config"
 )
 
-type Config struct {
+// Profile 单个集群的连接配置
+type Profile struct {
 	Address   []string `json:"address"`
 	PDAddress []string `json:"pd_address"`
 	User      string   `json:"user"`
 	Password  string   `json:"passwd"`
+
+	// API 版本：V1、V1TTL 或 V2，为空时使用 V2
+	APIVersion string `json:"api_version,omitempty"`
+	// API V2 下使用的 keyspace，为空时使用默认 keyspace
+	Keyspace string `json:"keyspace,omitempty"`
+
+	// TLS/mTLS 配置，为空时使用明文连接
+	CAPath        string   `json:"ca_path,omitempty"`
+	CertPath      string   `json:"cert_path,omitempty"`
+	KeyPath       string   `json:"key_path,omitempty"`
+	CertAllowedCN []string `json:"cert_allowed_cn,omitempty"`
+
+	// 连接超时时间，如 "10s"，为空时使用默认值
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+
+	// 只读模式，连接时用 dao.ReadOnlyKv 包装，所有写入和删除都会被拒绝
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// 受保护的key前缀，交互界面中删除、保存或添加匹配的key时按策略拒绝或要求确认
+	ProtectedPrefixes []ProtectedPrefix `json:"protected_prefixes,omitempty"`
+}
+
+// ProtectedPrefix 受保护的key前缀配置
+type ProtectedPrefix struct {
+	Prefix string `json:"prefix"`
+	// prefix 的编码：utf8、hex 或 base64，为空时使用 utf8
+	Encoding string `json:"encoding,omitempty"`
+	// 策略：deny 禁止修改，confirm 需要确认，type-key 需要输入完整的key名确认
+	Policy string `json:"policy"`
+}
+
+type Config struct {
+	// 顶层字段作为默认集群配置，兼容只有单个集群的旧配置文件
+	Profile
+
+	// 命名的集群配置，未指定 --profile 时使用 default_profile
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// defaultProfileName 顶层配置对应的 profile 名称
+const defaultProfileName = "default"
+
+// GetProfile 按名称查找 profile，name 为空时使用 default_profile，
+// 都未指定时使用顶层配置。返回的是副本，可以直接用命令行参数覆盖
+func (c *Config) GetProfile(name string) (string, *Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+
+	if profile, ok := c.Profiles[name]; ok && profile != nil {
+		p := *profile
+		return name, &p, nil
+	}
+
+	if name == "" || name == defaultProfileName {
+		p := c.Profile
+		return defaultProfileName, &p, nil
+	}
+
+	return "", nil, fmt.Errorf("profile %q not found in config, available profiles: %s", name, strings.Join(c.ProfileNames(), ", "))
+}
+
+// ProfileNames 返回按名称排序的所有 profile，顶层配置存在时包含 default
+func (c *Config) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	if _, ok := c.Profiles[defaultProfileName]; !ok && len(c.PDAddress) > 0 {
+		names = append(names, defaultProfileName)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -52,6 +135,104 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// Security 根据配置生成 TiKV 客户端的 TLS 配置，未配置证书时返回 nil
+func (c *Profile) Security() (*tikvconfig.Security, error) {
+	if c.CAPath == "" && c.CertPath == "" && c.KeyPath == "" {
+		return nil, nil
+	}
+
+	if c.CAPath == "" {
+		return nil, fmt.Errorf("ca_path is required when TLS is enabled")
+	}
+	if (c.CertPath == "") != (c.KeyPath == "") {
+		return nil, fmt.Errorf("cert_path and key_path must be specified together")
+	}
+
+	security := tikvconfig.NewSecurity(c.CAPath, c.CertPath, c.KeyPath, c.CertAllowedCN)
+	return &security, nil
+}
+
+// ApiVersion 解析配置中的 API 版本，返回规范化的版本名和对应的客户端选项
+func (c *Profile) ApiVersion() (string, client.CliOpt, error) {
+	return parseApiVersion(c.APIVersion)
+}
+
+// ClientOpts 根据 profile 生成客户端选项，同时返回规范化的 API 版本名
+func (c *Profile) ClientOpts() (string, []client.CliOpt, error) {
+	versionName, versionOpt, err := c.ApiVersion()
+	if err != nil {
+		return "", nil, err
+	}
+
+	security, err := c.Security()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid TLS config: %v", err)
+	}
+
+	opts := []client.CliOpt{versionOpt}
+	if c.ConnectTimeout != "" {
+		timeout, err := time.ParseDuration(c.ConnectTimeout)
+		if err != nil || timeout <= 0 {
+			return "", nil, fmt.Errorf("invalid connect_timeout %q", c.ConnectTimeout)
+		}
+		opts = append(opts, client.WithConnectTimeout(timeout))
+	}
+	if c.Keyspace != "" {
+		if versionName != "V2" {
+			return "", nil, fmt.Errorf("keyspace %q requires API V2, current API version is %s", c.Keyspace, versionName)
+		}
+		opts = append(opts, client.WithKeyspace(c.Keyspace))
+	}
+	if security != nil {
+		opts = append(opts, client.WithTls(security))
+	}
+
+	return versionName, opts, nil
+}
+
+// Protected 解析配置中的受保护前缀
+func (c *Profile) Protected() ([]ui.ProtectedPrefix, error) {
+	var rules []ui.ProtectedPrefix
+	for _, p := range c.ProtectedPrefixes {
+		prefix, err := decodeKeyArg(p.Prefix, p.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protected prefix: %v", err)
+		}
+		policy, err := parseProtectPolicy(p.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("protected prefix %q: %v", p.Prefix, err)
+		}
+		rules = append(rules, ui.ProtectedPrefix{Prefix: prefix, Policy: policy})
+	}
+	return rules, nil
+}
+
+func parseProtectPolicy(policy string) (ui.ProtectPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case "deny":
+		return ui.ProtectDeny, nil
+	case "confirm":
+		return ui.ProtectConfirm, nil
+	case "type-key":
+		return ui.ProtectTypeKey, nil
+	default:
+		return 0, fmt.Errorf("unknown policy %q, expected deny, confirm or type-key", policy)
+	}
+}
+
+func parseApiVersion(version string) (string, client.CliOpt, error) {
+	switch strings.ToUpper(strings.TrimSpace(version)) {
+	case "", "V2", "2":
+		return "V2", client.WithApiVersionV2(), nil
+	case "V1", "1":
+		return "V1", client.WithApiVersionV1(), nil
+	case "V1TTL", "V1_TTL":
+		return "V1TTL", client.WithApiVersionV1TTL(), nil
+	default:
+		return "", nil, fmt.Errorf("unknown api version %q, expected V1, V1TTL or V2", version)
+	}
+}
+
 func SaveConfig(configPath string, config *Config) error {
 	file, err := os.Create(configPath)
 	if err != nil {
@@ -70,9 +251,12 @@ func SaveConfig(configPath string, config *Config) error {
 
 func getDefaultConfig() *Config {
 	return &Config{
-		Address:   []string{"172.16.0.10:2379"},
-		PDAddress: []string{"172.16.0.10:2379"},
-		User:      "",
-		Password:  "",
+		Profile: Profile{
+			Address:    []string{"172.16.0.10:2379"},
+			PDAddress:  []string{"172.16.0.10:2379"},
+			User:       "",
+			Password:   "",
+			APIVersion: "V2",
+		},
 	}
 }
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/baixiaoshi/tikvtool/client"
+	"github.com/baixiaoshi/tikvtool/dao"
+
+	"github.com/pingcap/log"
+	"go.uber.org/zap/zapcore"
+)
+
+// connectProfile 按 profile 的配置连接集群，返回客户端和规范化的 API 版本名
+func connectProfile(ctx context.Context, name string, profile *Profile) (*client.RawKvClient, string, error) {
+	if len(profile.PDAddress) == 0 {
+		return nil, "", fmt.Errorf("no PD endpoints specified for profile %q", name)
+	}
+
+	versionName, cliOpts, err := profile.ClientOpts()
+	if err != nil {
+		return nil, "", err
+	}
+
+	cli, err := client.NewRawKvClient(ctx, profile.PDAddress, cliOpts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot connect to TiKV cluster %q: %v", name, err)
+	}
+
+	return cli, versionName, nil
+}
+
+// kvConn 非交互式子命令使用的集群连接
+type kvConn struct {
+	dao.KV // 只读 profile 下为 dao.ReadOnlyKv，写入和删除返回 dao.ErrReadOnly
+
+	raw        *dao.RawKv
+	profile    string // profile 名称
+	apiVersion string // 规范化的 API 版本名
+	keyspace   string
+}
+
+// Close 关闭到集群的连接
+func (c *kvConn) Close() error {
+	return c.raw.Close()
+}
+
+// ClusterID 集群 ID
+func (c *kvConn) ClusterID() uint64 {
+	return c.raw.ClusterID()
+}
+
+// PutWithTTL 写入带过期时间的key，只读模式下返回 dao.ErrReadOnly
+func (c *kvConn) PutWithTTL(ctx context.Context, key, val []byte, ttl uint64) error {
+	if dao.IsReadOnly(c.KV) {
+		return dao.ErrReadOnly
+	}
+	return c.raw.PutWithTTL(ctx, key, val, ttl)
+}
+
+// checkWritable 只读 profile 返回错误。写入类子命令在读取数据、创建备份之前调用，
+// 避免做完准备工作后才在写入时失败
+func (c *kvConn) checkWritable() error {
+	if dao.IsReadOnly(c.KV) {
+		return fmt.Errorf("profile %q is read-only, use a profile without read_only to write", c.profile)
+	}
+	return nil
+}
+
+// openKv 按命令行参数和配置文件连接集群，供非交互式子命令使用
+func openKv(ctx context.Context) (*kvConn, error) {
+	quietClientLogs()
+
+	_, name, profile, err := loadProfile()
+	if err != nil {
+		return nil, err
+	}
+	return openProfileKv(ctx, name, profile)
+}
+
+// openNamedKv 连接指定名称的 profile，name 为空或与 --profile 相同时使用合并了命令行参数的配置
+func openNamedKv(ctx context.Context, name string) (*kvConn, error) {
+	if name == "" || name == profileName {
+		return openKv(ctx)
+	}
+	quietClientLogs()
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+	name, profile, err := config.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	// --read-only 对所有连接生效
+	if readOnly {
+		profile.ReadOnly = true
+	}
+	return openProfileKv(ctx, name, profile)
+}
+
+// openProfileKv 连接指定 profile 的集群
+func openProfileKv(ctx context.Context, name string, profile *Profile) (*kvConn, error) {
+	cli, versionName, err := connectProfile(ctx, name, profile)
+	if err != nil {
+		return nil, err
+	}
+	return newKvConn(name, profile, cli, versionName), nil
+}
+
+// newKvConn 使用已建立的连接创建 kvConn，profile 设置了 read_only 时
+// 通过 dao.ReadOnlyKv 拒绝所有写入，profiles list 中显示的只读状态由此生效
+func newKvConn(name string, profile *Profile, cli *client.RawKvClient, versionName string) *kvConn {
+	raw := dao.NewRawKv(cli)
+	conn := &kvConn{
+		KV:         raw,
+		raw:        raw,
+		profile:    name,
+		apiVersion: versionName,
+		keyspace:   profile.Keyspace,
+	}
+	if profile.ReadOnly {
+		conn.KV = dao.NewReadOnlyKv(raw)
+	}
+	return conn
+}
+
+// quietClientLogs 将 client-go 的日志输出到标准错误并只保留错误日志，
+// 避免污染子命令的标准输出
+func quietClientLogs() {
+	stderr := zapcore.AddSync(os.Stderr)
+	logger, props, err := log.InitLoggerWithWriteSyncer(&log.Config{Level: "error"}, stderr, stderr)
+	if err != nil {
+		return
+	}
+	log.ReplaceGlobals(logger, props)
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
+)
+
+var (
+	copyFromProfile   string
+	copyToProfile     string
+	copyPrefix        string
+	copyStart         string
+	copyEnd           string
+	copyKeyEncoding   string
+	copyRewritePrefix string
+	copyBatchSize     int
+	copyRate          int
+	copyNoVerify      bool
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy a key range between clusters or prefixes",
+	Long: `Copy the keys with a prefix or in [start, end) from one profile to another,
+or to another prefix of the same cluster with --rewrite-prefix old:new.
+
+Existing keys in the destination are overwritten. After copying, the destination
+range is scanned again and its key count and checksum are compared with the source.`,
+	Args: cobra.NoArgs,
+	RunE: runCopy,
+}
+
+func init() {
+	copyCmd.Flags().StringVar(&copyFromProfile, "from-profile", "", "profile to read from (default is --profile)")
+	copyCmd.Flags().StringVar(&copyToProfile, "to-profile", "", "profile to write to (default is --profile)")
+	addRangeFlags(copyCmd, &copyPrefix, &copyStart, &copyEnd, &copyKeyEncoding)
+	copyCmd.Flags().StringVar(&copyRewritePrefix, "rewrite-prefix", "", "replace the key prefix old with new when writing, as old:new (requires --prefix)")
+	copyCmd.Flags().IntVar(&copyBatchSize, "batch-size", 256, "number of pairs read and written per request")
+	copyCmd.Flags().IntVar(&copyRate, "rate", 0, "maximum number of pairs written per second, 0 for no limit")
+	copyCmd.Flags().BoolVar(&copyNoVerify, "no-verify", false, "skip the final count and checksum comparison")
+	rootCmd.AddCommand(copyCmd)
+}
+
+// prefixRewrite 把key的前缀 from 替换为 to
+type prefixRewrite struct {
+	from []byte
+	to   []byte
+}
+
+// parsePrefixRewrite 解析 old:new 形式的前缀替换，old 和 new 按 --key-encoding 解码
+func parsePrefixRewrite(s, encoding string) (*prefixRewrite, error) {
+	if s == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid --rewrite-prefix %q, expected old:new", s)
+	}
+	var rw prefixRewrite
+	var err error
+	if rw.from, err = decodeKeyArg(from, encoding); err != nil {
+		return nil, err
+	}
+	if rw.to, err = decodeKeyArg(to, encoding); err != nil {
+		return nil, err
+	}
+	return &rw, nil
+}
+
+// apply 返回替换前缀后的新key，rw 为空时原样返回
+func (rw *prefixRewrite) apply(key []byte) []byte {
+	if rw == nil {
+		return key
+	}
+	out := make([]byte, 0, len(key)-len(rw.from)+len(rw.to))
+	out = append(out, rw.to...)
+	return append(out, key[len(rw.from):]...)
+}
+
+// rangeChecksum 按顺序累计键值对的数量和 SHA-256 校验和
+type rangeChecksum struct {
+	count int
+	hash  hash.Hash
+	buf   [binary.MaxVarintLen64]byte
+}
+
+func newRangeChecksum() *rangeChecksum {
+	return &rangeChecksum{hash: sha256.New()}
+}
+
+func (c *rangeChecksum) add(key, value []byte) {
+	for _, data := range [][]byte{key, value} {
+		n := binary.PutUvarint(c.buf[:], uint64(len(data)))
+		c.hash.Write(c.buf[:n])
+		c.hash.Write(data)
+	}
+	c.count++
+}
+
+func (c *rangeChecksum) sum() string {
+	return hex.EncodeToString(c.hash.Sum(nil))[:16]
+}
+
+func runCopy(cmd *cobra.Command, args []string) error {
+	r, err := parseRange(copyPrefix, copyStart, copyEnd, copyKeyEncoding)
+	if err != nil {
+		return err
+	}
+	rw, err := parsePrefixRewrite(copyRewritePrefix, copyKeyEncoding)
+	if err != nil {
+		return err
+	}
+	if copyBatchSize <= 0 || copyBatchSize > maxPageSize || copyRate < 0 {
+		return fmt.Errorf("--batch-size must be between 1 and %d and --rate must not be negative", maxPageSize)
+	}
+
+	// 目标范围：替换前缀时只支持 --prefix，且前缀必须以 old 开头
+	dst := r
+	if rw != nil {
+		if copyPrefix == "" {
+			return fmt.Errorf("--rewrite-prefix requires --prefix")
+		}
+		if !bytes.HasPrefix(r.start, rw.from) {
+			return fmt.Errorf("--prefix must start with the prefix being rewritten")
+		}
+		newPrefix := rw.apply(r.start)
+		dst = keyRange{start: newPrefix, end: dao.PrefixEnd(newPrefix)}
+	}
+
+	ctx := context.Background()
+	src, err := openNamedKv(ctx, copyFromProfile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	target, err := openNamedKv(ctx, copyToProfile)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+	if err := target.checkWritable(); err != nil {
+		return err
+	}
+
+	if src.ClusterID() == target.ClusterID() && src.keyspace == target.keyspace && rangesOverlap(r, dst) {
+		return fmt.Errorf("source range %s and destination range %s overlap in the same cluster", formatRange(r), formatRange(dst))
+	}
+
+	var limiter *rate.Limiter
+	if copyRate > 0 {
+		burst := copyRate
+		if burst < copyBatchSize {
+			burst = copyBatchSize
+		}
+		limiter = rate.NewLimiter(rate.Limit(copyRate), burst)
+	}
+
+	fmt.Fprintf(os.Stderr, "Copying %s from %q to %s in %q\n", formatRange(r), src.profile, formatRange(dst), target.profile)
+
+	started := time.Now()
+	lastReport := started
+	written := newRangeChecksum()
+	_, err = walkRange(ctx, src.KV, r, copyBatchSize, 0, false, false, func(keys, vals [][]byte) error {
+		newKeys := make([][]byte, len(keys))
+		for i, key := range keys {
+			newKeys[i] = rw.apply(key)
+			written.add(newKeys[i], vals[i])
+		}
+		if limiter != nil {
+			if err := limiter.WaitN(ctx, len(keys)); err != nil {
+				return err
+			}
+		}
+		if err := target.BatchPut(ctx, newKeys, vals); err != nil {
+			return fmt.Errorf("batch put failed after %d pairs: %v", written.count-len(keys), err)
+		}
+		if time.Since(lastReport) > 2*time.Second {
+			fmt.Fprintf(os.Stderr, "Copied %d pairs (%.0f pairs/s)\n", written.count, float64(written.count)/time.Since(started).Seconds())
+			lastReport = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Copied %d pairs in %v\n", written.count, time.Since(started).Round(time.Millisecond))
+
+	if copyNoVerify {
+		return nil
+	}
+
+	// 重新扫描目标范围，比较数量和校验和
+	verified := newRangeChecksum()
+	_, err = walkRange(ctx, target.KV, dst, defaultPageSize, 0, false, false, func(keys, vals [][]byte) error {
+		for i, key := range keys {
+			verified.add(key, vals[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify destination: %v", err)
+	}
+
+	fmt.Printf("Source:      %d pairs, checksum %s\n", written.count, written.sum())
+	fmt.Printf("Destination: %d pairs, checksum %s\n", verified.count, verified.sum())
+	if verified.count != written.count || verified.sum() != written.sum() {
+		return fmt.Errorf("destination does not match the source, it may contain other keys or have been modified during the copy")
+	}
+	fmt.Println("Destination matches the source.")
+	return nil
+}
+
+// rangesOverlap 判断两个范围是否有交集，空的 end 表示不限制
+func rangesOverlap(a, b keyRange) bool {
+	aBeforeB := len(a.end) > 0 && bytes.Compare(a.end, b.start) <= 0
+	bBeforeA := len(b.end) > 0 && bytes.Compare(b.end, a.start) <= 0
+	return !aBeforeB && !bBeforeA
+}
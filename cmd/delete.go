@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/baixiaoshi/tikvtool/dump"
+	"github.com/baixiaoshi/tikvtool/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteKeyEncoding string
+	deleteYes         bool
+	deleteBackup      string
+
+	deleteRangePrefix      string
+	deleteRangeStart       string
+	deleteRangeEnd         string
+	deleteRangeKeyEncoding string
+	deleteRangeYes         bool
+	deleteRangeBackup      string
+	deleteRangeSample      int
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <key>",
+	Short: "Delete a key (dry run unless --yes is given)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDelete,
+}
+
+var deleteRangeCmd = &cobra.Command{
+	Use:   "delete-range",
+	Short: "Delete all keys in a range (dry run unless --yes is given)",
+	Long: `Delete all keys with a prefix or in [start, end).
+
+Without --yes only the number of affected keys and a sample of them are shown.
+With --backup the affected pairs are written to a dump file before deleting, which can
+be restored with the import command; keys written by others between the backup and the
+delete are not included.`,
+	Args: cobra.NoArgs,
+	RunE: runDeleteRange,
+}
+
+func init() {
+	deleteCmd.Flags().StringVar(&deleteKeyEncoding, "key-encoding", keyEncodingUTF8, "encoding of the key argument: utf8, hex or base64")
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "actually delete the key")
+	deleteCmd.Flags().StringVar(&deleteBackup, "backup", "", "write the key and its value to this file before deleting")
+	rootCmd.AddCommand(deleteCmd)
+
+	addRangeFlags(deleteRangeCmd, &deleteRangePrefix, &deleteRangeStart, &deleteRangeEnd, &deleteRangeKeyEncoding)
+	deleteRangeCmd.Flags().BoolVarP(&deleteRangeYes, "yes", "y", false, "actually delete the keys")
+	deleteRangeCmd.Flags().StringVar(&deleteRangeBackup, "backup", "", "write the affected pairs to this file before deleting")
+	deleteRangeCmd.Flags().IntVar(&deleteRangeSample, "sample", 10, "number of affected keys to show in dry run mode")
+	rootCmd.AddCommand(deleteRangeCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	key, err := decodeKeyArg(args[0], deleteKeyEncoding)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	kv, err := openKv(ctx)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+	// 只读 profile 仍然可以预览要删除的数据
+	if deleteYes {
+		if err := kv.checkWritable(); err != nil {
+			return err
+		}
+	}
+
+	value, err := kv.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get key: %v", err)
+	}
+	display := utils.DisplayKey(key, utils.EncodingAuto)
+	if value == nil {
+		return &exitCodeError{code: exitNotFound, err: fmt.Errorf("key %s not found", display)}
+	}
+
+	if !deleteYes {
+		fmt.Printf("Dry run: key %s (%d bytes) would be deleted.\n", display, len(value))
+		fmt.Println("Re-run with --yes to delete it.")
+		return nil
+	}
+
+	if deleteBackup != "" {
+		backup, err := createBackup(deleteBackup, kv, keyRange{start: key, end: append(append([]byte(nil), key...), 0)})
+		if err != nil {
+			return err
+		}
+		if err := backup.write([][]byte{key}, [][]byte{value}); err != nil {
+			backup.abort()
+			return err
+		}
+		if err := backup.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("Backed up key %s to %s\n", display, deleteBackup)
+	}
+
+	if err := kv.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete key: %v", err)
+	}
+	fmt.Printf("Deleted key %s\n", display)
+	return nil
+}
+
+func runDeleteRange(cmd *cobra.Command, args []string) error {
+	r, err := parseRange(deleteRangePrefix, deleteRangeStart, deleteRangeEnd, deleteRangeKeyEncoding)
+	if err != nil {
+		return err
+	}
+	if len(r.end) == 0 {
+		// TiKV 把空的结束key当作不限制，为避免误删整个集群要求显式指定上界
+		return fmt.Errorf("delete-range requires an upper bound, use --prefix or --end")
+	}
+
+	ctx := context.Background()
+	kv, err := openKv(ctx)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+	if deleteRangeYes {
+		if err := kv.checkWritable(); err != nil {
+			return err
+		}
+	}
+
+	rangeText := formatRange(r)
+
+	if !deleteRangeYes {
+		var sample [][]byte
+		total, err := walkRange(ctx, kv.KV, r, defaultPageSize, 0, true, false, func(keys, vals [][]byte) error {
+			for _, key := range keys {
+				if len(sample) < deleteRangeSample {
+					sample = append(sample, key)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Dry run: %d keys in range %s would be deleted.\n", total, rangeText)
+		if len(sample) > 0 {
+			fmt.Printf("First %d keys:\n", len(sample))
+			for _, key := range sample {
+				fmt.Printf("  %s\n", utils.DisplayKey(key, utils.EncodingAuto))
+			}
+		}
+		if total > 0 {
+			fmt.Println("Re-run with --yes to delete them.")
+		}
+		return nil
+	}
+
+	total, err := countOrBackupRange(ctx, kv, r, deleteRangeBackup)
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		fmt.Printf("No keys in range %s, nothing to delete.\n", rangeText)
+		return nil
+	}
+
+	if err := kv.DeleteRange(ctx, r.start, r.end); err != nil {
+		return fmt.Errorf("failed to delete range: %v", err)
+	}
+	fmt.Printf("Deleted %d keys in range %s\n", total, rangeText)
+	return nil
+}
+
+// countOrBackupRange 统计范围内的key数量，指定了备份文件时同时备份键值对
+func countOrBackupRange(ctx context.Context, kv *kvConn, r keyRange, path string) (int, error) {
+	if path == "" {
+		return walkRange(ctx, kv.KV, r, defaultPageSize, 0, true, false, func(keys, vals [][]byte) error {
+			return nil
+		})
+	}
+
+	backup, err := createBackup(path, kv, r)
+	if err != nil {
+		return 0, err
+	}
+	total, err := walkRange(ctx, kv.KV, r, defaultPageSize, 0, false, false, backup.write)
+	if err != nil {
+		backup.abort()
+		return 0, err
+	}
+	if err := backup.Close(); err != nil {
+		return 0, err
+	}
+	fmt.Printf("Backed up %d keys to %s\n", total, path)
+	return total, nil
+}
+
+// backupFile 删除前保存键值对的导出文件，格式和压缩方式按扩展名确定
+type backupFile struct {
+	path   string
+	file   *os.File
+	writer *dump.Writer
+}
+
+// createBackup 创建备份文件，文件已存在时报错，避免覆盖之前的备份
+func createBackup(path string, kv *kvConn, r keyRange) (*backupFile, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %v", err)
+	}
+	writer, err := dump.NewWriter(file, dump.FormatJSONL, dump.CompressionFromPath(path), dumpHeader(kv, r))
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write backup: %v", err)
+	}
+	return &backupFile{path: path, file: file, writer: writer}, nil
+}
+
+// abort 备份失败时关闭并删除文件，不留下看起来完整的半截备份，重新执行相同的命令也不会因为文件已存在而失败
+func (b *backupFile) abort() {
+	b.file.Close()
+	os.Remove(b.path)
+}
+
+func (b *backupFile) write(keys, vals [][]byte) error {
+	for i, key := range keys {
+		if err := b.writer.Write(key, vals[i]); err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+	}
+	return nil
+}
+
+// Close 将备份写入磁盘后关闭文件，确保删除前备份已经落盘。失败时删除文件
+func (b *backupFile) Close() error {
+	if err := b.writer.Close(); err != nil {
+		b.abort()
+		return fmt.Errorf("failed to write backup: %v", err)
+	}
+	if err := b.file.Sync(); err != nil {
+		b.abort()
+		return fmt.Errorf("failed to write backup: %v", err)
+	}
+	if err := b.file.Close(); err != nil {
+		os.Remove(b.path)
+		return fmt.Errorf("failed to write backup: %v", err)
+	}
+	return nil
+}
+
+// formatRange 以 [start, end) 的形式显示范围
+func formatRange(r keyRange) string {
+	return fmt.Sprintf("[%s, %s)", displayBound(r.start), displayBound(r.end))
+}
+
+func displayBound(key []byte) string {
+	if len(key) == 0 {
+		return `""`
+	}
+	return utils.DisplayKey(key, utils.EncodingAuto)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/baixiaoshi/tikvtool/client/mockstore"
+	"github.com/baixiaoshi/tikvtool/dao"
+)
+
+// failingScanKv 第 failOn 次 Scan 返回错误，之前的页面正常返回
+type failingScanKv struct {
+	dao.KV
+	failOn int
+	calls  int
+}
+
+func (f *failingScanKv) Scan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	f.calls++
+	if f.calls == f.failOn {
+		return nil, nil, errors.New("region unavailable")
+	}
+	return f.KV.Scan(ctx, startKey, endKey, limit)
+}
+
+func TestBackupRemovedWhenScanFails(t *testing.T) {
+	ctx := context.Background()
+	mem := dao.NewMemKv()
+	for i := 0; i < defaultPageSize+10; i++ {
+		putTestKeys(t, mem, "v", fmt.Sprintf("key/%05d", i))
+	}
+	conn := newKvConn("test", &Profile{}, mockstore.NewCluster(t).NewV1Client(t), "V1")
+	// 第一页已经写入备份之后扫描失败
+	conn.KV = &failingScanKv{KV: mem, failOn: 2}
+	path := filepath.Join(t.TempDir(), "backup.jsonl")
+	r := keyRange{start: []byte("key/"), end: dao.PrefixEnd([]byte("key/"))}
+
+	if _, err := countOrBackupRange(ctx, conn, r, path); err == nil {
+		t.Fatal("countOrBackupRange succeeded although the scan failed")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("backup file left behind after a failed scan: %v", err)
+	}
+
+	// 重新执行相同的命令不会因为文件已存在而失败
+	conn.KV = mem
+	total, err := countOrBackupRange(ctx, conn, r, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != defaultPageSize+10 {
+		t.Fatalf("backed up %d keys, want %d", total, defaultPageSize+10)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("backup file missing after a successful run: %v", err)
+	}
+}
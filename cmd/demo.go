@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+	"github.com/baixiaoshi/tikvtool/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// demoCluster 演示模式下标题栏显示的集群名称
+const demoCluster = "demo"
+
+// demoProtected 演示数据中的受保护前缀，覆盖三种策略
+var demoProtected = []ui.ProtectedPrefix{
+	{Prefix: []byte("user/"), Policy: ui.ProtectConfirm},
+	{Prefix: []byte("config/"), Policy: ui.ProtectTypeKey},
+	{Prefix: []byte("session/"), Policy: ui.ProtectDeny},
+}
+
+// runDemo 使用内存存储启动交互界面，不需要连接集群，修改在退出后丢弃
+func runDemo() error {
+	ctx := context.Background()
+	kv := dao.NewMemKv()
+	if err := seedDemoData(ctx, kv); err != nil {
+		return err
+	}
+
+	// 使用 --read-only 时可以体验只读模式
+	var store dao.KV = kv
+	if readOnly {
+		store = dao.NewReadOnlyKv(kv)
+	}
+
+	model := ui.InitialModel(ctx, store, ui.WithCluster(&ui.Cluster{
+		Name:       demoCluster,
+		ApiVersion: "V2",
+		KV:         store,
+		Protected:  demoProtected,
+	}))
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to start UI: %v", err)
+	}
+	return nil
+}
+
+// seedDemoData 写入演示数据，覆盖界面支持的各种格式：JSON、YAML、TOML、纯文本和二进制，
+// 以及足够多的key用于体验分页加载
+func seedDemoData(ctx context.Context, kv dao.KV) error {
+	var keys, vals [][]byte
+	add := func(key string, value []byte) {
+		keys = append(keys, []byte(key))
+		vals = append(vals, value)
+	}
+
+	names := []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi"}
+	for i, name := range names {
+		roles := `["reader"]`
+		if i == 0 {
+			roles = `["reader","admin"]`
+		}
+		add(fmt.Sprintf("user/%04d", i+1), []byte(fmt.Sprintf(
+			`{"id":%d,"name":%q,"email":"%s@example.com","active":%t,"roles":%s}`,
+			i+1, name, name, i%3 != 0, roles)))
+	}
+
+	for i := 1; i <= 250; i++ {
+		add(fmt.Sprintf("order/%06d", i), []byte(fmt.Sprintf(
+			`{"order_id":%d,"user_id":%d,"amount":%d.%02d,"status":%q}`,
+			i, i%len(names)+1, i*7%500, i%100, []string{"pending", "paid", "shipped"}[i%3])))
+	}
+
+	add("config/app.yaml", []byte("server:\n  host: 0.0.0.0\n  port: 8080\nfeatures:\n  - search\n  - export\n"))
+	add("config/db.toml", []byte("[database]\nhost = \"10.0.0.12\"\nport = 4000\nmax_connections = 64\n"))
+	add("session/7f3a9c", []byte("user=alice; expires=2030-01-01T00:00:00Z"))
+	add("notes/welcome", []byte("Welcome to the tikvtool demo.\nChanges made here are kept in memory only."))
+	add("blob/thumbnail", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00, 0x00, 0x0d, 'I', 'H', 'D', 'R', 0x00, 0x00, 0x00, 0x10})
+	add("idx/\x00\x00\x00\x01\xff", []byte("binary key"))
+
+	return kv.BatchPut(ctx, keys, vals)
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+	"github.com/baixiaoshi/tikvtool/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffLeftProfile  string
+	diffRightProfile string
+	diffPrefix       string
+	diffStart        string
+	diffEnd          string
+	diffKeyEncoding  string
+	diffRightPrefix  string
+	diffPageSize     int
+	diffSummaryOnly  bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare a key range between two clusters or two prefixes",
+	Long: `Compare the keys with a prefix or in [start, end) on two profiles, or the keys
+under two prefixes of the same cluster with --right-prefix, by scanning both sides
+in key order.
+
+Keys only on the left are shown with "-", keys only on the right with "+" and keys
+whose values differ with "~". Values that are JSON on both sides are compared
+structurally, so a change in formatting or field order alone is reported as such.
+
+The exit code is 0 when both sides are identical and 3 when they differ.`,
+	Args: cobra.NoArgs,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffLeftProfile, "left-profile", "", "profile of the left side (default is --profile)")
+	diffCmd.Flags().StringVar(&diffRightProfile, "right-profile", "", "profile of the right side (default is --profile)")
+	addRangeFlags(diffCmd, &diffPrefix, &diffStart, &diffEnd, &diffKeyEncoding)
+	diffCmd.Flags().StringVar(&diffRightPrefix, "right-prefix", "", "compare --prefix with this prefix on the right side")
+	diffCmd.Flags().IntVar(&diffPageSize, "page-size", defaultPageSize, "number of keys to read per request")
+	diffCmd.Flags().BoolVar(&diffSummaryOnly, "summary", false, "only print the number of differences")
+	rootCmd.AddCommand(diffCmd)
+}
+
+// rangeCursor 按key顺序逐条读取一个范围，读完一页后自动读取下一页。
+// 比较时使用去掉 prefix 之后的key，使两个不同前缀下的数据可以对齐
+type rangeCursor struct {
+	kv       dao.KV
+	r        keyRange
+	prefix   []byte
+	pageSize int
+
+	keys [][]byte
+	vals [][]byte
+	pos  int
+	done bool
+}
+
+// peek 返回当前的键值对，范围读完时 ok 为 false
+func (c *rangeCursor) peek(ctx context.Context) (key, value []byte, ok bool, err error) {
+	for c.pos >= len(c.keys) {
+		if c.done {
+			return nil, nil, false, nil
+		}
+		page, err := dao.ScanPage(ctx, c.kv, c.r.start, c.r.end, c.pageSize)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("scan failed: %v", err)
+		}
+		c.keys, c.vals, c.pos = page.Keys, page.Vals, 0
+		c.done = !page.HasMore
+		c.r.start = page.Next
+	}
+	return c.keys[c.pos], c.vals[c.pos], true, nil
+}
+
+func (c *rangeCursor) advance() {
+	c.pos++
+}
+
+// relative 返回去掉前缀之后用于比较的key
+func (c *rangeCursor) relative(key []byte) []byte {
+	return key[len(c.prefix):]
+}
+
+// diffStats 比较结果的统计
+type diffStats struct {
+	onlyLeft  int
+	onlyRight int
+	changed   int
+	identical int
+}
+
+func (s *diffStats) differ() bool {
+	return s.onlyLeft+s.onlyRight+s.changed > 0
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	left, err := parseRange(diffPrefix, diffStart, diffEnd, diffKeyEncoding)
+	if err != nil {
+		return err
+	}
+	if diffPageSize <= 0 || diffPageSize > maxPageSize {
+		return fmt.Errorf("invalid page size %d, must be between 1 and %d", diffPageSize, maxPageSize)
+	}
+
+	right := left
+	var leftPrefix, rightPrefix []byte
+	if diffRightPrefix != "" {
+		if diffPrefix == "" {
+			return fmt.Errorf("--right-prefix requires --prefix")
+		}
+		if rightPrefix, err = decodeKeyArg(diffRightPrefix, diffKeyEncoding); err != nil {
+			return err
+		}
+		leftPrefix = left.start
+		right = keyRange{start: rightPrefix, end: dao.PrefixEnd(rightPrefix)}
+	}
+
+	ctx := context.Background()
+	leftKv, err := openNamedKv(ctx, diffLeftProfile)
+	if err != nil {
+		return err
+	}
+	defer leftKv.Close()
+	rightKv, err := openNamedKv(ctx, diffRightProfile)
+	if err != nil {
+		return err
+	}
+	defer rightKv.Close()
+
+	if leftKv.ClusterID() == rightKv.ClusterID() && leftKv.keyspace == rightKv.keyspace && bytes.Equal(leftPrefix, rightPrefix) {
+		return fmt.Errorf("both sides read the same range of the same cluster, use --right-profile or --right-prefix")
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	fmt.Fprintf(out, "--- %s %s\n", leftKv.profile, formatRange(left))
+	fmt.Fprintf(out, "+++ %s %s\n", rightKv.profile, formatRange(right))
+
+	lc := &rangeCursor{kv: leftKv.KV, r: left, prefix: leftPrefix, pageSize: diffPageSize}
+	rc := &rangeCursor{kv: rightKv.KV, r: right, prefix: rightPrefix, pageSize: diffPageSize}
+	return reportDiff(ctx, out, lc, rc, diffSummaryOnly)
+}
+
+// reportDiff 比较两侧并输出差异和统计，存在差异时返回退出码为 exitDiffer 的错误
+func reportDiff(ctx context.Context, out *bufio.Writer, lc, rc *rangeCursor, summaryOnly bool) error {
+	stats, err := diffRanges(ctx, lc, rc, func(line string) {
+		if !summaryOnly {
+			fmt.Fprintln(out, line)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d only in left, %d only in right, %d changed, %d identical\n",
+		stats.onlyLeft, stats.onlyRight, stats.changed, stats.identical)
+	if stats.differ() {
+		out.Flush()
+		return &exitCodeError{code: exitDiffer, err: fmt.Errorf("ranges differ")}
+	}
+	return nil
+}
+
+// diffRanges 同时按顺序扫描两侧，像归并一样逐个比较key，每个差异通过 emit 输出
+func diffRanges(ctx context.Context, lc, rc *rangeCursor, emit func(line string)) (*diffStats, error) {
+	stats := &diffStats{}
+	for {
+		lkey, lval, lok, err := lc.peek(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("left side: %v", err)
+		}
+		rkey, rval, rok, err := rc.peek(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("right side: %v", err)
+		}
+		if !lok && !rok {
+			return stats, nil
+		}
+
+		cmp := 0
+		switch {
+		case !lok:
+			cmp = 1
+		case !rok:
+			cmp = -1
+		default:
+			cmp = bytes.Compare(lc.relative(lkey), rc.relative(rkey))
+		}
+
+		switch {
+		case cmp < 0:
+			stats.onlyLeft++
+			emit("- " + utils.DisplayKey(lkey, utils.EncodingAuto))
+			lc.advance()
+		case cmp > 0:
+			stats.onlyRight++
+			emit("+ " + utils.DisplayKey(rkey, utils.EncodingAuto))
+			rc.advance()
+		default:
+			if bytes.Equal(lval, rval) {
+				stats.identical++
+			} else {
+				stats.changed++
+				emit("~ " + utils.DisplayKey(lkey, utils.EncodingAuto))
+				for _, line := range diffValues(lval, rval) {
+					emit("    " + line)
+				}
+			}
+			lc.advance()
+			rc.advance()
+		}
+	}
+}
+
+// diffValues 描述两个value的差异，两侧都是 JSON 时逐字段比较
+func diffValues(left, right []byte) []string {
+	if utils.IsText(left) && utils.IsText(right) &&
+		utils.DetectFormat(string(left)) == utils.FormatJSON && utils.DetectFormat(string(right)) == utils.FormatJSON {
+		diffs, err := utils.JSONDiff(string(left), string(right))
+		if err == nil {
+			if len(diffs) == 0 {
+				return []string{"(JSON formatting only)"}
+			}
+			return diffs
+		}
+	}
+	return []string{
+		"- " + previewValue(left),
+		"+ " + previewValue(right),
+	}
+}
+
+// previewValue 单行显示value，过长时截断
+func previewValue(value []byte) string {
+	const maxLen = 100
+	text := utils.DisplayKey(value, utils.EncodingAuto)
+	if runes := []rune(text); len(runes) > maxLen {
+		text = string(runes[:maxLen-3]) + "..."
+	}
+	return fmt.Sprintf("%s (%d bytes)", text, len(value))
+}
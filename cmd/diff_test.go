@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+)
+
+// newPairStore 按 key、value 交替的参数创建内存存储
+func newPairStore(t *testing.T, pairs ...string) *dao.MemKv {
+	t.Helper()
+	kv := dao.NewMemKv()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if err := kv.Put(context.Background(), []byte(pairs[i]), []byte(pairs[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return kv
+}
+
+func TestDiffRanges(t *testing.T) {
+	all := keyRange{}
+	tests := []struct {
+		name        string
+		left, right []string
+		leftRange   keyRange
+		rightRange  keyRange
+		leftPrefix  string
+		rightPrefix string
+		pageSize    int
+		want        []string
+		wantStats   diffStats
+	}{
+		{
+			name:      "identical across pages",
+			left:      []string{"a", "1", "b", "2", "c", "3", "d", "4", "e", "5"},
+			right:     []string{"a", "1", "b", "2", "c", "3", "d", "4", "e", "5"},
+			pageSize:  2,
+			wantStats: diffStats{identical: 5},
+		},
+		{
+			// 两侧的分页边界不同，归并时不能漏掉或重复比较key
+			name:     "merge across pages",
+			left:     []string{"a", "1", "b", "2", "d", "4", "f", "6", "g", "7"},
+			right:    []string{"b", "2", "c", "3", "d", "x", "e", "5", "g", "7", "h", "8"},
+			pageSize: 2,
+			want: []string{
+				"- a",
+				"+ c",
+				"~ d",
+				"    - 4 (1 bytes)",
+				"    + x (1 bytes)",
+				"+ e",
+				"- f",
+				"+ h",
+			},
+			wantStats: diffStats{onlyLeft: 2, onlyRight: 3, changed: 1, identical: 2},
+		},
+		{
+			name:      "left side empty",
+			right:     []string{"a", "1", "b", "2", "c", "3"},
+			pageSize:  1,
+			want:      []string{"+ a", "+ b", "+ c"},
+			wantStats: diffStats{onlyRight: 3},
+		},
+		{
+			name:     "json values",
+			left:     []string{"user/1", `{"name":"alice","age":30}`, "user/2", `{"a":1,"b":2}`},
+			right:    []string{"user/1", `{"age":31,"name":"alice"}`, "user/2", `{ "b": 2, "a": 1.0 }`},
+			pageSize: 10,
+			want: []string{
+				"~ user/1",
+				"    $.age: 30 -> 31",
+				"~ user/2",
+				"    (JSON formatting only)",
+			},
+			wantStats: diffStats{changed: 2},
+		},
+		{
+			// 同一集群的两个前缀按去掉前缀后的相对key对齐，前缀范围之外的key不参与比较
+			name:        "right prefix",
+			left:        []string{"old/a", "1", "old/b", "2", "old/c", "3", "new/b", "2", "new/c", "x", "new/d", "4", "old", "-", "new0", "-"},
+			leftRange:   keyRange{start: []byte("old/"), end: dao.PrefixEnd([]byte("old/"))},
+			rightRange:  keyRange{start: []byte("new/"), end: dao.PrefixEnd([]byte("new/"))},
+			leftPrefix:  "old/",
+			rightPrefix: "new/",
+			pageSize:    2,
+			want: []string{
+				"- old/a",
+				"~ old/c",
+				"    - 3 (1 bytes)",
+				"    + x (1 bytes)",
+				"+ new/d",
+			},
+			wantStats: diffStats{onlyLeft: 1, onlyRight: 1, changed: 1, identical: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leftKv := newPairStore(t, tt.left...)
+			rightKv := leftKv
+			if tt.rightPrefix == "" {
+				rightKv = newPairStore(t, tt.right...)
+			}
+			if tt.leftRange.start == nil {
+				tt.leftRange, tt.rightRange = all, all
+			}
+			lc := &rangeCursor{kv: leftKv, r: tt.leftRange, prefix: []byte(tt.leftPrefix), pageSize: tt.pageSize}
+			rc := &rangeCursor{kv: rightKv, r: tt.rightRange, prefix: []byte(tt.rightPrefix), pageSize: tt.pageSize}
+
+			var lines []string
+			stats, err := diffRanges(context.Background(), lc, rc, func(line string) {
+				lines = append(lines, line)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("diff lines:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if *stats != tt.wantStats {
+				t.Errorf("stats = %+v, want %+v", *stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestReportDiffExitCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right []string
+		summaryOnly bool
+		wantCode    int
+		wantOutput  string
+	}{
+		{
+			name:       "identical",
+			left:       []string{"a", "1", "b", "2"},
+			right:      []string{"a", "1", "b", "2"},
+			wantOutput: "0 only in left, 0 only in right, 0 changed, 2 identical\n",
+		},
+		{
+			name:       "differ",
+			left:       []string{"a", "1", "b", "2"},
+			right:      []string{"a", "1", "c", "3"},
+			wantCode:   exitDiffer,
+			wantOutput: "- b\n+ c\n1 only in left, 1 only in right, 0 changed, 1 identical\n",
+		},
+		{
+			name:        "differ with summary",
+			left:        []string{"a", "1"},
+			right:       []string{"a", "2"},
+			summaryOnly: true,
+			wantCode:    exitDiffer,
+			wantOutput:  "0 only in left, 0 only in right, 1 changed, 0 identical\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := &rangeCursor{kv: newPairStore(t, tt.left...), pageSize: 1}
+			rc := &rangeCursor{kv: newPairStore(t, tt.right...), pageSize: 1}
+			var buf bytes.Buffer
+			out := bufio.NewWriter(&buf)
+			err := reportDiff(context.Background(), out, lc, rc, tt.summaryOnly)
+			out.Flush()
+
+			code := 0
+			var codeErr *exitCodeError
+			if errors.As(err, &codeErr) {
+				code = codeErr.code
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d (err %v), want %d", code, err, tt.wantCode)
+			}
+			if buf.String() != tt.wantOutput {
+				t.Errorf("output = %q, want %q", buf.String(), tt.wantOutput)
+			}
+		})
+	}
+}
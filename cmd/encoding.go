@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// 命令行中key的编码方式
+const (
+	keyEncodingUTF8   = "utf8"
+	keyEncodingHex    = "hex"
+	keyEncodingBase64 = "base64"
+)
+
+// decodeKeyArg 按 --key-encoding 将命令行参数解析为原始字节
+func decodeKeyArg(arg, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", keyEncodingUTF8:
+		return []byte(arg), nil
+	case keyEncodingHex:
+		s := strings.TrimPrefix(strings.TrimPrefix(arg, "0x"), "0X")
+		key, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex key %q: %v", arg, err)
+		}
+		return key, nil
+	case keyEncodingBase64:
+		key, err := base64.StdEncoding.DecodeString(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 key %q: %v", arg, err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unknown key encoding %q, expected utf8, hex or base64", encoding)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/baixiaoshi/tikvtool/dump"
+	"github.com/baixiaoshi/tikvtool/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	getKeyEncoding string
+	getOutput      string
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a key",
+	Long: `Print the value of a key without starting the interactive explorer.
+
+Exits with status 2 when the key does not exist, so scripts can branch on it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGet,
+}
+
+func init() {
+	getCmd.Flags().StringVar(&getKeyEncoding, "key-encoding", keyEncodingUTF8, "encoding of the key argument: utf8, hex or base64")
+	getCmd.Flags().StringVarP(&getOutput, "output", "o", "raw", "output format: raw, pretty or json")
+	rootCmd.AddCommand(getCmd)
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	key, err := decodeKeyArg(args[0], getKeyEncoding)
+	if err != nil {
+		return err
+	}
+
+	output := strings.ToLower(getOutput)
+	if output != "raw" && output != "pretty" && output != "json" {
+		return fmt.Errorf("unknown output format %q, expected raw, pretty or json", getOutput)
+	}
+
+	ctx := context.Background()
+	kv, err := openKv(ctx)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	value, err := kv.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get key: %v", err)
+	}
+	if value == nil {
+		return &exitCodeError{
+			code: exitNotFound,
+			err:  fmt.Errorf("key %s not found", utils.DisplayKey(key, utils.EncodingAuto)),
+		}
+	}
+
+	switch output {
+	case "raw":
+		_, err = os.Stdout.Write(value)
+	case "pretty":
+		err = printPretty(value)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(dump.NewRecord(key, value))
+	}
+	return err
+}
+
+// printPretty 格式化输出 value：文本按检测到的格式美化，二进制数据输出十六进制视图
+func printPretty(value []byte) error {
+	if utils.IsText(value) {
+		formatted, _ := utils.FormatContent(string(value))
+		_, err := fmt.Println(formatted)
+		return err
+	}
+
+	rows := (len(value) + utils.HexDumpWidth - 1) / utils.HexDumpWidth
+	for _, line := range utils.HexDump(value, 0, rows) {
+		if _, err := fmt.Println(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,491 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+	"github.com/baixiaoshi/tikvtool/dump"
+	"github.com/baixiaoshi/tikvtool/utils"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+var (
+	importBatchSize    int
+	importConcurrency  int
+	importRate         int
+	importCheckpoint   string
+	importSkipExisting bool
+	importOverwrite    bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import key/value pairs from a dump file",
+	Long: `Import the pairs in a dump file written by export (or by delete --backup)
+with BatchPut. JSON Lines files without a header, such as the output of scan, are
+accepted as well. Use - to read from stdin.
+
+Progress is saved to a checkpoint file (<file>.checkpoint by default) so that an
+interrupted import resumes where it stopped when run again; the checkpoint is
+removed after a successful import. Batches that may have been written before the
+interruption are replayed, and keys among them that already exist are skipped.
+The checkpoint records the target cluster and keyspace, and is refused when the
+import is run against another target.
+
+By default the import stops if a key already exists in the cluster; use
+--skip-existing to keep existing values or --overwrite to replace them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().IntVar(&importBatchSize, "batch-size", 256, "number of pairs written per BatchPut")
+	importCmd.Flags().IntVar(&importConcurrency, "concurrency", 4, "number of batches written in parallel")
+	importCmd.Flags().IntVar(&importRate, "rate", 0, "maximum number of pairs written per second, 0 for no limit")
+	importCmd.Flags().StringVar(&importCheckpoint, "checkpoint", "", "checkpoint file for resuming (default <file>.checkpoint)")
+	importCmd.Flags().BoolVar(&importSkipExisting, "skip-existing", false, "keep the value of keys that already exist")
+	importCmd.Flags().BoolVar(&importOverwrite, "overwrite", false, "replace the value of keys that already exist")
+	rootCmd.AddCommand(importCmd)
+}
+
+// checkpointState 导入进度，前 Records 条记录都已写入。前 Dispatched 条记录已经交给
+// 写入协程，Records 之后的部分可能已经全部或部分写入，继续导入时这部分已存在的key直接跳过。
+// File 到 CreatedAt 标识 dump 文件，Target 开头的字段标识导入的目标集群和 keyspace
+type checkpointState struct {
+	File            string    `json:"file"`
+	Size            int64     `json:"size"`
+	ClusterID       uint64    `json:"cluster_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	TargetClusterID uint64    `json:"target_cluster_id"`
+	TargetKeyspace  string    `json:"target_keyspace"`
+	Records         int64     `json:"records"`
+	Dispatched      int64     `json:"dispatched"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// matches 检查进度文件是否属于同一个 dump 文件
+func (c *checkpointState) matches(other *checkpointState) bool {
+	return c.File == other.File && c.Size == other.Size &&
+		c.ClusterID == other.ClusterID && c.CreatedAt.Equal(other.CreatedAt)
+}
+
+// sameTarget 检查进度文件是否记录的是导入到同一个集群和 keyspace 的进度
+func (c *checkpointState) sameTarget(other *checkpointState) bool {
+	return c.TargetClusterID == other.TargetClusterID && c.TargetKeyspace == other.TargetKeyspace
+}
+
+func loadCheckpoint(path string) (*checkpointState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	var state checkpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+// saveCheckpoint 先写临时文件再重命名，避免中断时留下不完整的进度文件
+func saveCheckpoint(path string, state *checkpointState) error {
+	state.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	if importSkipExisting && importOverwrite {
+		return fmt.Errorf("--skip-existing and --overwrite cannot be used together")
+	}
+	if importBatchSize <= 0 || importConcurrency <= 0 || importRate < 0 {
+		return fmt.Errorf("--batch-size and --concurrency must be positive and --rate must not be negative")
+	}
+
+	var in io.Reader = os.Stdin
+	var state *checkpointState
+	checkpointPath := importCheckpoint
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open dump file: %v", err)
+		}
+		defer file.Close()
+		in = file
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		abs, _ := filepath.Abs(path)
+		state = &checkpointState{File: abs, Size: info.Size()}
+		if checkpointPath == "" {
+			checkpointPath = path + ".checkpoint"
+		}
+	} else if checkpointPath != "" {
+		return fmt.Errorf("--checkpoint cannot be used when reading from stdin")
+	}
+
+	reader, err := dump.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to read dump file: %v", err)
+	}
+	defer reader.Close()
+
+	if reader.HasHeader() {
+		h := reader.Header()
+		fmt.Fprintf(os.Stderr, "Dump of cluster %d (API %s) range %s, created at %s\n",
+			h.ClusterID, h.APIVersion, formatRange(keyRange{start: h.Start, end: h.End}), h.CreatedAt.Format(time.RFC3339))
+		if state != nil {
+			state.ClusterID, state.CreatedAt = h.ClusterID, h.CreatedAt
+		}
+	}
+
+	ctx := context.Background()
+	kv, err := openKv(ctx)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+	if err := kv.checkWritable(); err != nil {
+		return err
+	}
+
+	// 从上次中断的位置继续，进度文件必须属于同一个 dump 文件和同一个导入目标
+	var replayEnd int64
+	if state != nil {
+		state.TargetClusterID, state.TargetKeyspace = kv.ClusterID(), kv.keyspace
+		if replayEnd, err = resumeImport(reader, state, checkpointPath); err != nil {
+			return err
+		}
+	}
+
+	imp := &importer{
+		kv:             kv,
+		reader:         reader,
+		batchSize:      importBatchSize,
+		concurrency:    importConcurrency,
+		skipExisting:   importSkipExisting,
+		overwrite:      importOverwrite,
+		state:          state,
+		checkpointPath: checkpointPath,
+		replayEnd:      replayEnd,
+	}
+	if importRate > 0 {
+		burst := importRate
+		if burst < importBatchSize {
+			burst = importBatchSize
+		}
+		imp.limiter = rate.NewLimiter(rate.Limit(importRate), burst)
+	}
+
+	if err := imp.run(ctx); err != nil {
+		if state != nil && (state.Records > 0 || state.Dispatched > 0) {
+			return fmt.Errorf("%v (progress saved to %s, run the same command again to resume)", err, checkpointPath)
+		}
+		return err
+	}
+
+	if state != nil {
+		os.Remove(checkpointPath)
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d pairs, skipped %d existing keys in %v\n",
+		imp.imported, imp.skipped, time.Since(imp.started).Round(time.Millisecond))
+	return nil
+}
+
+// resumeImport 读取进度文件并跳过已经写入的记录，返回可能已在上次导入中写入的记录范围的结束位置
+func resumeImport(reader *dump.Reader, state *checkpointState, checkpointPath string) (int64, error) {
+	saved, err := loadCheckpoint(checkpointPath)
+	if err != nil || saved == nil {
+		return 0, err
+	}
+	if !saved.matches(state) {
+		return 0, fmt.Errorf("checkpoint %s belongs to a different dump file, remove it to start over", checkpointPath)
+	}
+	if !saved.sameTarget(state) {
+		return 0, fmt.Errorf("checkpoint %s records an import into cluster %d keyspace %s, not cluster %d keyspace %s, remove it to start over",
+			checkpointPath, saved.TargetClusterID, keyspaceName(saved.TargetKeyspace), state.TargetClusterID, keyspaceName(state.TargetKeyspace))
+	}
+
+	replayEnd := saved.Dispatched
+	if replayEnd < saved.Records {
+		replayEnd = saved.Records
+	}
+	state.Records = saved.Records
+	state.Dispatched = replayEnd
+
+	fmt.Fprintf(os.Stderr, "Resuming from checkpoint %s: skipping %d imported pairs\n", checkpointPath, saved.Records)
+	if replayEnd > saved.Records {
+		fmt.Fprintf(os.Stderr, "The next %d pairs may have been written before the interruption, existing keys among them are skipped\n", replayEnd-saved.Records)
+	}
+	for i := int64(0); i < saved.Records; i++ {
+		if _, _, err := reader.Next(); err != nil {
+			return 0, fmt.Errorf("failed to skip imported pairs: %v", err)
+		}
+	}
+	return replayEnd, nil
+}
+
+// keyspaceName 显示 keyspace 名称，空名称为默认 keyspace
+func keyspaceName(keyspace string) string {
+	if keyspace == "" {
+		return "<default>"
+	}
+	return strconv.Quote(keyspace)
+}
+
+// importer 并发写入 dump 文件中的数据
+type importer struct {
+	kv           dao.KV
+	reader       *dump.Reader
+	limiter      *rate.Limiter
+	batchSize    int
+	concurrency  int
+	skipExisting bool
+	overwrite    bool
+
+	mu             sync.Mutex       // 保护 state 和进度文件
+	state          *checkpointState // 为空时不记录进度
+	checkpointPath string
+	// 序号小于 replayEnd 的记录可能已经在上次导入中写入，已存在时跳过而不是报错
+	replayEnd int64
+
+	started  time.Time
+	imported int64
+	skipped  int64
+}
+
+// importBatch 一批要写入的数据，seq 为批次序号，first 为第一条记录在 dump 文件中的序号
+type importBatch struct {
+	seq   int
+	first int64
+	keys  [][]byte
+	vals  [][]byte
+}
+
+// importResult 一批数据的写入结果
+type importResult struct {
+	seq      int
+	size     int
+	imported int
+	skipped  int
+}
+
+func (imp *importer) run(ctx context.Context) error {
+	imp.started = time.Now()
+	g, ctx := errgroup.WithContext(ctx)
+	batches := make(chan importBatch, imp.concurrency)
+	results := make(chan importResult, imp.concurrency)
+
+	var pos, reserved int64
+	if imp.state != nil {
+		pos, reserved = imp.state.Records, imp.state.Dispatched
+	}
+
+	// 读取 dump 文件并分批
+	g.Go(func() error {
+		defer close(batches)
+		send := func(batch importBatch) error {
+			// 交给写入协程之前先把这批数据记入进度文件，进程在任何时候中断，
+			// 继续导入时都知道哪些记录可能已经写入
+			if end := batch.first + int64(len(batch.keys)); end > reserved {
+				reserved = end
+				if err := imp.reserve(reserved); err != nil {
+					return err
+				}
+			}
+			select {
+			case batches <- batch:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		batch := importBatch{first: pos}
+		for {
+			key, value, err := imp.reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read dump file: %v", err)
+			}
+			if value == nil {
+				return fmt.Errorf("record %s has no value, keys-only listings cannot be imported",
+					utils.DisplayKey(key, utils.EncodingAuto))
+			}
+			batch.keys = append(batch.keys, key)
+			batch.vals = append(batch.vals, value)
+			pos++
+			if len(batch.keys) < imp.batchSize {
+				continue
+			}
+			if err := send(batch); err != nil {
+				return err
+			}
+			batch = importBatch{seq: batch.seq + 1, first: pos}
+		}
+		if len(batch.keys) > 0 {
+			return send(batch)
+		}
+		return nil
+	})
+
+	// 并发写入
+	workers, wctx := errgroup.WithContext(ctx)
+	for i := 0; i < imp.concurrency; i++ {
+		workers.Go(func() error {
+			for batch := range batches {
+				result, err := imp.write(wctx, batch)
+				if err != nil {
+					return err
+				}
+				select {
+				case results <- result:
+				case <-wctx.Done():
+					return wctx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	g.Go(func() error {
+		defer close(results)
+		return workers.Wait()
+	})
+
+	// 汇总结果，只有连续完成的批次才计入进度
+	g.Go(func() error {
+		done := make(map[int]importResult)
+		next := 0
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		lastSaved := time.Now()
+		for {
+			select {
+			case result, ok := <-results:
+				if !ok {
+					return imp.saveProgress()
+				}
+				imp.imported += int64(result.imported)
+				imp.skipped += int64(result.skipped)
+				done[result.seq] = result
+				imp.mu.Lock()
+				for r, ok := done[next]; ok; r, ok = done[next] {
+					delete(done, next)
+					if imp.state != nil {
+						imp.state.Records += int64(r.size)
+					}
+					next++
+				}
+				imp.mu.Unlock()
+				if time.Since(lastSaved) > time.Second {
+					if err := imp.saveProgress(); err != nil {
+						return err
+					}
+					lastSaved = time.Now()
+				}
+			case <-ticker.C:
+				elapsed := time.Since(imp.started).Seconds()
+				fmt.Fprintf(os.Stderr, "Imported %d pairs, skipped %d (%.0f pairs/s)\n",
+					imp.imported, imp.skipped, float64(imp.imported+imp.skipped)/elapsed)
+			}
+		}
+	})
+
+	err := g.Wait()
+	if err != nil {
+		// 失败时保存已经连续完成的进度，以便下次继续
+		imp.saveProgress()
+	}
+	return err
+}
+
+// write 按冲突策略写入一批数据
+func (imp *importer) write(ctx context.Context, batch importBatch) (importResult, error) {
+	result := importResult{seq: batch.seq, size: len(batch.keys)}
+	keys, vals := batch.keys, batch.vals
+
+	if !imp.overwrite {
+		existing, err := imp.kv.BatchGet(ctx, keys)
+		if err != nil {
+			return result, fmt.Errorf("failed to check existing keys: %v", err)
+		}
+		var newKeys, newVals [][]byte
+		for i, old := range existing {
+			if old == nil {
+				newKeys = append(newKeys, keys[i])
+				newVals = append(newVals, vals[i])
+				continue
+			}
+			// 上次导入中断前可能已经写入的key，不属于冲突
+			if !imp.skipExisting && batch.first+int64(i) >= imp.replayEnd {
+				return result, fmt.Errorf("key %s already exists, use --skip-existing or --overwrite",
+					utils.DisplayKey(keys[i], utils.EncodingAuto))
+			}
+			result.skipped++
+		}
+		keys, vals = newKeys, newVals
+	}
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+	if imp.limiter != nil {
+		if err := imp.limiter.WaitN(ctx, len(keys)); err != nil {
+			return result, err
+		}
+	}
+	if err := imp.kv.BatchPut(ctx, keys, vals); err != nil {
+		return result, fmt.Errorf("batch put failed: %v", err)
+	}
+	result.imported = len(keys)
+	return result, nil
+}
+
+// reserve 记录前 n 条记录已经交给写入协程
+func (imp *importer) reserve(n int64) error {
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+	if imp.state == nil || n <= imp.state.Dispatched {
+		return nil
+	}
+	imp.state.Dispatched = n
+	return saveCheckpoint(imp.checkpointPath, imp.state)
+}
+
+func (imp *importer) saveProgress() error {
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+	if imp.state == nil || (imp.state.Records == 0 && imp.state.Dispatched == 0) {
+		return nil
+	}
+	return saveCheckpoint(imp.checkpointPath, imp.state)
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+	"github.com/baixiaoshi/tikvtool/dump"
+)
+
+// writeTestDump 写入 n 条 key/NNN 记录的导出文件
+func writeTestDump(t *testing.T, n int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.dump")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w, err := dump.NewWriter(file, dump.FormatJSONL, dump.CompressionNone, dump.Header{ClusterID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := w.Write([]byte(fmt.Sprintf("key/%03d", i)), []byte(fmt.Sprintf("value %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestImporter 与 runImport 相同地打开导出文件，存在进度文件时从中断处继续
+func newTestImporter(t *testing.T, kv dao.KV, path string) *importer {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := dump.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { reader.Close() })
+
+	state := &checkpointState{
+		File:            path,
+		Size:            info.Size(),
+		ClusterID:       reader.Header().ClusterID,
+		CreatedAt:       reader.Header().CreatedAt,
+		TargetClusterID: testTargetCluster,
+	}
+	checkpointPath := path + ".checkpoint"
+	replayEnd, err := resumeImport(reader, state, checkpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &importer{
+		kv:             kv,
+		reader:         reader,
+		batchSize:      10,
+		concurrency:    4,
+		state:          state,
+		checkpointPath: checkpointPath,
+		replayEnd:      replayEnd,
+	}
+}
+
+// testTargetCluster newTestImporter 使用的导入目标集群 ID
+const testTargetCluster = 7
+
+// flakyKv 第 failOn 次 BatchPut 只写入一半数据后返回错误，模拟写入过程中断
+type flakyKv struct {
+	dao.KV
+	failOn int64
+	calls  atomic.Int64
+}
+
+func (f *flakyKv) BatchPut(ctx context.Context, keys, vals [][]byte) error {
+	if f.calls.Add(1) == f.failOn {
+		half := len(keys) / 2
+		if err := f.KV.BatchPut(ctx, keys[:half], vals[:half]); err != nil {
+			return err
+		}
+		return errors.New("connection reset")
+	}
+	return f.KV.BatchPut(ctx, keys, vals)
+}
+
+func putTestKeys(t *testing.T, kv dao.KV, value string, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		if err := kv.Put(context.Background(), []byte(key), []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	path := writeTestDump(t, 30)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		skipExisting bool
+		overwrite    bool
+		wantErr      bool
+		wantValue    string
+		wantSkipped  int64
+	}{
+		{name: "default", wantErr: true, wantValue: "old"},
+		{name: "skip existing", skipExisting: true, wantValue: "old", wantSkipped: 1},
+		{name: "overwrite", overwrite: true, wantValue: "value 15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(path + ".checkpoint")
+			kv := dao.NewMemKv()
+			putTestKeys(t, kv, "old", "key/015")
+
+			imp := newTestImporter(t, kv, path)
+			imp.skipExisting, imp.overwrite = tt.skipExisting, tt.overwrite
+			err := imp.run(ctx)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "key/015 already exists") {
+					t.Fatalf("run() = %v, want a conflict on key/015", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if v, _ := kv.Get(ctx, []byte("key/015")); string(v) != tt.wantValue {
+				t.Errorf("key/015 = %q, want %q", v, tt.wantValue)
+			}
+			if imp.skipped != tt.wantSkipped {
+				t.Errorf("skipped = %d, want %d", imp.skipped, tt.wantSkipped)
+			}
+			if !tt.wantErr && kv.Len() != 30 {
+				t.Errorf("store has %d keys, want 30", kv.Len())
+			}
+		})
+	}
+}
+
+func TestImportResumeAfterInterruption(t *testing.T) {
+	path := writeTestDump(t, 100)
+	ctx := context.Background()
+	mem := dao.NewMemKv()
+
+	// 第 3 次写入中途失败，其他批次可能已经在它之后完成
+	imp := newTestImporter(t, &flakyKv{KV: mem, failOn: 3}, path)
+	if err := imp.run(ctx); err == nil {
+		t.Fatal("run() with a failing BatchPut succeeded")
+	}
+	saved, err := loadCheckpoint(path + ".checkpoint")
+	if err != nil || saved == nil {
+		t.Fatalf("checkpoint after failure = %+v, %v", saved, err)
+	}
+	if saved.Dispatched < saved.Records || int(saved.Dispatched) < mem.Len() {
+		t.Fatalf("checkpoint records=%d dispatched=%d, but %d keys were written", saved.Records, saved.Dispatched, mem.Len())
+	}
+
+	// 使用默认的冲突策略继续导入，已经写入的key不应被当作冲突
+	imp = newTestImporter(t, mem, path)
+	if imp.replayEnd != saved.Dispatched {
+		t.Fatalf("replayEnd = %d, want %d", imp.replayEnd, saved.Dispatched)
+	}
+	if err := imp.run(ctx); err != nil {
+		t.Fatalf("resumed run() = %v", err)
+	}
+	if mem.Len() != 100 {
+		t.Fatalf("store has %d keys after resuming, want 100", mem.Len())
+	}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key/%03d", i)
+		if v, _ := mem.Get(ctx, []byte(key)); string(v) != fmt.Sprintf("value %d", i) {
+			t.Fatalf("%s = %q after resuming", key, v)
+		}
+	}
+}
+
+func TestImportResumeOnlySkipsReplayedRecords(t *testing.T) {
+	path := writeTestDump(t, 40)
+	ctx := context.Background()
+	mem := dao.NewMemKv()
+
+	// 上次导入完成了前 10 条，交给写入协程的有 20 条，其中 key/012 已经写入
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCheckpoint(path+".checkpoint", &checkpointState{File: path, Size: info.Size(), ClusterID: 1, TargetClusterID: testTargetCluster, Records: 10, Dispatched: 20}); err != nil {
+		t.Fatal(err)
+	}
+	putTestKeys(t, mem, "written", "key/012")
+	// key/025 在上次导入之前就存在，仍然是冲突
+	putTestKeys(t, mem, "old", "key/025")
+
+	imp := newTestImporter(t, mem, path)
+	err = imp.run(ctx)
+	if err == nil || !strings.Contains(err.Error(), "key/025 already exists") {
+		t.Fatalf("run() = %v, want a conflict on key/025", err)
+	}
+	if v, _ := mem.Get(ctx, []byte("key/000")); v != nil {
+		t.Fatalf("key/000 = %q, records before the checkpoint should not be imported again", v)
+	}
+	if v, _ := mem.Get(ctx, []byte("key/015")); string(v) != "value 15" {
+		t.Fatalf("key/015 = %q, want it imported", v)
+	}
+}
+
+func TestImportResumeRequiresSameTarget(t *testing.T) {
+	path := writeTestDump(t, 20)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := &checkpointState{File: path, Size: info.Size(), ClusterID: 1, TargetClusterID: testTargetCluster, TargetKeyspace: "users", Records: 10, Dispatched: 10}
+
+	tests := []struct {
+		name     string
+		cluster  uint64
+		keyspace string
+		wantErr  bool
+	}{
+		{name: "same target", cluster: testTargetCluster, keyspace: "users"},
+		{name: "other cluster", cluster: 8, keyspace: "users", wantErr: true},
+		{name: "other keyspace", cluster: testTargetCluster, keyspace: "orders", wantErr: true},
+		{name: "default keyspace", cluster: testTargetCluster, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := saveCheckpoint(path+".checkpoint", saved); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			reader, err := dump.NewReader(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+
+			state := &checkpointState{File: path, Size: info.Size(), ClusterID: 1, TargetClusterID: tt.cluster, TargetKeyspace: tt.keyspace}
+			_, err = resumeImport(reader, state, path+".checkpoint")
+			if tt.wantErr {
+				// 导入到其他目标时不能跳过前面的记录
+				if err == nil || !strings.Contains(err.Error(), "remove it to start over") {
+					t.Fatalf("resumeImport against another target = %v, want it refused", err)
+				}
+				if state.Records != 0 {
+					t.Fatalf("state.Records = %d after a refused resume", state.Records)
+				}
+				return
+			}
+			if err != nil || state.Records != 10 {
+				t.Fatalf("resumeImport = %v, records %d, want to resume from 10", err, state.Records)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage connection profiles",
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List connection profiles in the config file",
+	Args:  cobra.NoArgs,
+	RunE:  runProfilesList,
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
+
+func runProfilesList(cmd *cobra.Command, args []string) error {
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
+	names := config.ProfileNames()
+	if len(names) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No profiles configured.")
+		return nil
+	}
+
+	// 当前默认使用的 profile
+	current, _, _ := config.GetProfile(profileName)
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tPD ENDPOINTS\tAPI\tKEYSPACE\tTLS\tREAD-ONLY")
+	for _, name := range names {
+		_, profile, err := config.GetProfile(name)
+		if err != nil {
+			return err
+		}
+
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		version, _, err := profile.ApiVersion()
+		if err != nil {
+			version = profile.APIVersion + "(invalid)"
+		}
+		keyspace := profile.Keyspace
+		if keyspace == "" {
+			keyspace = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			marker,
+			name,
+			strings.Join(profile.PDAddress, ","),
+			version,
+			keyspace,
+			yesNo(profile.CAPath != ""),
+			yesNo(profile.ReadOnly), // 连接时由 newKvConn 强制执行
+		)
+	}
+
+	return w.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
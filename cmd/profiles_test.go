@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baixiaoshi/tikvtool/client/mockstore"
+	"github.com/baixiaoshi/tikvtool/dao"
+
+	"github.com/spf13/cobra"
+)
+
+const testProfilesConfig = `{
+  "pd_address": ["127.0.0.1:2379"],
+  "profiles": {
+    "prod": {"pd_address": ["10.0.0.1:2379"], "read_only": true},
+    "staging": {"pd_address": ["10.0.1.1:2379"]}
+  }
+}`
+
+func loadTestConfig(t *testing.T) (string, *Config) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(testProfilesConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, config
+}
+
+func TestReadOnlyProfileIsEnforced(t *testing.T) {
+	_, config := loadTestConfig(t)
+	cluster := mockstore.NewCluster(t)
+	ctx := context.Background()
+
+	for _, name := range config.ProfileNames() {
+		name, profile, err := config.GetProfile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := newKvConn(name, profile, cluster.NewV1Client(t), "V1")
+
+		err = conn.Put(ctx, []byte(name+"/k"), []byte("v"))
+		if profile.ReadOnly {
+			if !errors.Is(err, dao.ErrReadOnly) || conn.checkWritable() == nil {
+				t.Errorf("%s: Put = %v on a read_only profile, want dao.ErrReadOnly", name, err)
+			}
+			if err := conn.PutWithTTL(ctx, []byte(name+"/k"), []byte("v"), 60); !errors.Is(err, dao.ErrReadOnly) {
+				t.Errorf("%s: PutWithTTL = %v on a read_only profile, want dao.ErrReadOnly", name, err)
+			}
+		} else if err != nil || conn.checkWritable() != nil {
+			t.Errorf("%s: Put = %v, checkWritable = %v, want writes allowed", name, err, conn.checkWritable())
+		}
+	}
+}
+
+func TestProfilesListShowsReadOnly(t *testing.T) {
+	path, _ := loadTestConfig(t)
+	oldConfig, oldProfile := configFile, profileName
+	configFile, profileName = path, ""
+	t.Cleanup(func() { configFile, profileName = oldConfig, oldProfile })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runProfilesList(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"default": "no", "prod": "yes", "staging": "no"}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[1:] {
+		fields := strings.Fields(strings.TrimPrefix(line, "*"))
+		name, readOnly := fields[0], fields[len(fields)-1]
+		if want[name] != readOnly {
+			t.Errorf("profile %s READ-ONLY = %s, want %s", name, readOnly, want[name])
+		}
+		delete(want, name)
+	}
+	if len(want) != 0 {
+		t.Errorf("profiles missing from the list: %v\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/baixiaoshi/tikvtool/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	putKeyEncoding string
+	putFile        string
+	putTTL         time.Duration
+	putValidate    bool
+	putOverwrite   bool
+)
+
+var putCmd = &cobra.Command{
+	Use:   "put <key> [value]",
+	Short: "Write a key",
+	Long: `Write a key without starting the interactive explorer.
+
+The value is taken from the argument, from --file, or from stdin when neither is given.
+An existing key is only replaced when --overwrite is given.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPut,
+}
+
+func init() {
+	putCmd.Flags().StringVar(&putKeyEncoding, "key-encoding", keyEncodingUTF8, "encoding of the key argument: utf8, hex or base64")
+	putCmd.Flags().StringVarP(&putFile, "file", "f", "", "read the value from a file (- for stdin)")
+	putCmd.Flags().DurationVar(&putTTL, "ttl", 0, "expire the key after this duration in whole seconds, requires API V1TTL or V2")
+	putCmd.Flags().BoolVar(&putValidate, "validate", false, "require the value to be a JSON or YAML object or array, or a TOML table")
+	putCmd.Flags().BoolVar(&putOverwrite, "overwrite", false, "replace the value if the key already exists")
+	rootCmd.AddCommand(putCmd)
+}
+
+// checkTTL TiKV 的 TTL 以秒为单位，不是整秒的时长会被截断，因此直接拒绝
+func checkTTL(ttl time.Duration) error {
+	if ttl < 0 || (ttl > 0 && ttl < time.Second) {
+		return fmt.Errorf("invalid ttl %v, must be at least 1s", ttl)
+	}
+	if ttl%time.Second != 0 {
+		return fmt.Errorf("invalid ttl %v, must be a whole number of seconds", ttl)
+	}
+	return nil
+}
+
+func runPut(cmd *cobra.Command, args []string) error {
+	key, err := decodeKeyArg(args[0], putKeyEncoding)
+	if err != nil {
+		return err
+	}
+
+	value, err := readPutValue(args)
+	if err != nil {
+		return err
+	}
+	if len(value) == 0 {
+		return fmt.Errorf("value is empty, TiKV does not store empty values")
+	}
+
+	if putValidate {
+		if utils.DetectStructuredFormat(string(value)) == utils.FormatPlainText {
+			return fmt.Errorf("value is not a JSON or YAML object or array, or a TOML table")
+		}
+	}
+
+	if err := checkTTL(putTTL); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	kv, err := openKv(ctx)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+	if err := kv.checkWritable(); err != nil {
+		return err
+	}
+
+	if putTTL > 0 && kv.apiVersion == "V1" {
+		return fmt.Errorf("--ttl requires API V1TTL or V2, current API version is %s", kv.apiVersion)
+	}
+
+	if !putOverwrite {
+		old, err := kv.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check existing key: %v", err)
+		}
+		if old != nil {
+			return fmt.Errorf("key %s already exists, use --overwrite to replace it", utils.DisplayKey(key, utils.EncodingAuto))
+		}
+	}
+
+	if putTTL > 0 {
+		err = kv.PutWithTTL(ctx, key, value, uint64(putTTL/time.Second))
+	} else {
+		err = kv.Put(ctx, key, value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put key: %v", err)
+	}
+
+	return nil
+}
+
+// readPutValue 依次从参数、--file 和标准输入读取 value，只能指定一个来源
+func readPutValue(args []string) ([]byte, error) {
+	if len(args) == 2 {
+		if putFile != "" {
+			return nil, fmt.Errorf("value argument and --file cannot be used together")
+		}
+		return []byte(args[1]), nil
+	}
+
+	if putFile != "" && putFile != "-" {
+		value, err := os.ReadFile(putFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read value file: %v", err)
+		}
+		return value, nil
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read value from stdin: %v", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckTTL(t *testing.T) {
+	tests := []struct {
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{ttl: 0},
+		{ttl: time.Second},
+		{ttl: 90 * time.Second},
+		{ttl: 24 * time.Hour},
+		{ttl: -time.Second, wantErr: true},
+		{ttl: 500 * time.Millisecond, wantErr: true},
+		{ttl: 1900 * time.Millisecond, wantErr: true}, // 不能截断为 1s
+		{ttl: time.Minute + time.Nanosecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := checkTTL(tt.ttl); (err != nil) != tt.wantErr {
+			t.Errorf("checkTTL(%v) = %v, want error %v", tt.ttl, err, tt.wantErr)
+		}
+	}
+}
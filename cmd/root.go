@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/baixiaoshi/tikvtool/client"
-	"github.com/baixiaoshi/tikvtool/dao"
 	"github.com/baixiaoshi/tikvtool/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,8 +14,18 @@ import (
 )
 
 var (
-	configFile string
-	endpoints  []string
+	configFile    string
+	profileName   string
+	endpoints     []string
+	caPath        string
+	certPath      string
+	keyPath       string
+	certAllowedCN []string
+	apiVersion    string
+	keyspace      string
+	timeout       time.Duration
+	readOnly      bool
+	demo          bool
 )
 
 var rootCmd = &cobra.Command{
@@ -24,53 +34,153 @@ var rootCmd = &cobra.Command{
 	Long: `A command-line tool for exploring TiKV keys interactively.
 Type key prefixes to search and browse your TiKV data in real-time.`,
 	RunE: runExplorer,
+
+	// 错误由 Execute 统一输出，避免连接失败时被用法说明淹没
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// 子命令使用的退出码，便于脚本根据结果分支
+const (
+	exitError    = 1 // 执行失败
+	exitNotFound = 2 // 要读取的key不存在
+	exitDiffer   = 3 // diff 发现了差异
+)
+
+// exitCodeError 指定退出码的错误
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+		var codeErr *exitCodeError
+		if errors.As(err, &codeErr) {
+			os.Exit(codeErr.code)
+		}
+		os.Exit(exitError)
 	}
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file (default is $HOME/.tikvtool.json)")
+	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", "connection profile to use (default is default_profile in config file)")
 	rootCmd.PersistentFlags().StringSliceVarP(&endpoints, "endpoints", "e", nil, "TiKV PD endpoints (overrides config file)")
+	rootCmd.PersistentFlags().StringVar(&caPath, "ca", "", "path of the CA certificate for TLS connections (overrides config file)")
+	rootCmd.PersistentFlags().StringVar(&certPath, "cert", "", "path of the client certificate for mTLS (overrides config file)")
+	rootCmd.PersistentFlags().StringVar(&keyPath, "key", "", "path of the client private key for mTLS (overrides config file)")
+	rootCmd.PersistentFlags().StringVar(&apiVersion, "api-version", "", "TiKV API version: V1, V1TTL or V2 (overrides config file, default V2)")
+	rootCmd.PersistentFlags().StringVar(&keyspace, "keyspace", "", "keyspace to use with API V2 (overrides config file)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "connect-timeout", 0, "timeout for connecting to the cluster (overrides config file, default 10s)")
+	rootCmd.PersistentFlags().StringSliceVar(&certAllowedCN, "cert-allowed-cn", nil, "allowed common names of the peer certificates (overrides config file)")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "refuse all writes and deletes, even if the profile allows them")
+	rootCmd.Flags().BoolVar(&demo, "demo", false, "explore an in-memory store with sample data instead of a cluster")
 }
 
-func runExplorer(cmd *cobra.Command, args []string) error {
-	// 加载配置
+// loadProfile 加载配置文件并解析要使用的 profile，命令行参数优先于配置文件
+func loadProfile() (*Config, string, *Profile, error) {
 	config, err := LoadConfig(configFile)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+		return nil, "", nil, fmt.Errorf("failed to load config: %v", err)
+	}
+
+	name, profile, err := config.GetProfile(profileName)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	// 如果命令行指定了endpoints，使用命令行的
-	pdEndpoints := config.PDAddress
 	if len(endpoints) > 0 {
-		pdEndpoints = endpoints
+		profile.PDAddress = endpoints
+	}
+
+	// 命令行指定的证书配置覆盖配置文件
+	if caPath != "" {
+		profile.CAPath = caPath
+	}
+	if certPath != "" {
+		profile.CertPath = certPath
+	}
+	if keyPath != "" {
+		profile.KeyPath = keyPath
+	}
+	if len(certAllowedCN) > 0 {
+		profile.CertAllowedCN = certAllowedCN
+	}
+	if apiVersion != "" {
+		profile.APIVersion = apiVersion
+	}
+	if keyspace != "" {
+		profile.Keyspace = keyspace
+	}
+	if timeout > 0 {
+		profile.ConnectTimeout = timeout.String()
+	}
+	// --read-only 只能开启只读，不能覆盖配置文件中的 read_only
+	if readOnly {
+		profile.ReadOnly = true
+	}
+
+	if len(profile.PDAddress) == 0 {
+		return nil, "", nil, fmt.Errorf("no PD endpoints specified for profile %q", name)
+	}
+
+	return config, name, profile, nil
+}
+
+func runExplorer(cmd *cobra.Command, args []string) error {
+	if demo {
+		return runDemo()
+	}
+
+	// 加载配置
+	config, name, profile, err := loadProfile()
+	if err != nil {
+		return err
 	}
 
-	if len(pdEndpoints) == 0 {
-		return fmt.Errorf("no PD endpoints specified")
+	versionName, _, err := profile.ApiVersion()
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Connecting to TiKV PD endpoints: %v\n", pdEndpoints)
+	if profile.ReadOnly {
+		fmt.Printf("Profile %q is read-only, writes and deletes are disabled\n", name)
+	}
+	if profile.CAPath != "" {
+		fmt.Printf("Connecting to TiKV cluster %q with TLS (API %s): %v\n", name, versionName, profile.PDAddress)
+	} else {
+		fmt.Printf("Connecting to TiKV cluster %q (API %s): %v\n", name, versionName, profile.PDAddress)
+	}
 
-	// 创建TiKV客户端
+	// 创建TiKV客户端，所有连接在退出时统一关闭
 	ctx := context.Background()
-	_, err = client.NewRawKvClient(ctx, pdEndpoints, client.WithApiVersionV2())
+	sess := newSession(config, name, profile)
+	defer sess.Close()
+
+	cluster, err := sess.Connect(ctx, name)
 	if err != nil {
-		return fmt.Errorf("failed to create TiKV client: %v", err)
+		return err
 	}
 
 	fmt.Println("Connected to TiKV successfully!")
 
-	// 创建DAO
-	kvClient := dao.NewRawKv()
-
 	// 启动交互式界面
-	model := ui.InitialModel(ctx, kvClient)
+	model := ui.InitialModel(ctx, cluster.KV,
+		ui.WithCluster(cluster),
+		ui.WithClusters(sess.ProfileNames(), sess.Connect),
+	)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+	"github.com/baixiaoshi/tikvtool/dump"
+	"github.com/baixiaoshi/tikvtool/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanPrefix      string
+	scanStart       string
+	scanEnd         string
+	scanKeyEncoding string
+	scanLimit       int
+	scanPageSize    int
+	scanReverse     bool
+	scanOutput      string
+	scanKeysOnly    bool
+	scanCountOnly   bool
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "List keys and values in a range",
+	Long: `Stream the keys and values in a range to stdout, page by page.
+
+The range is given either by --prefix or by --start/--end ([start, end)).
+TSV and CSV fields use the same escaping as the explorer's escaped display mode:
+backslashes are doubled and non-printable bytes are written as \xNN.`,
+	Args: cobra.NoArgs,
+	RunE: runScan,
+}
+
+func init() {
+	addRangeFlags(scanCmd, &scanPrefix, &scanStart, &scanEnd, &scanKeyEncoding)
+	scanCmd.Flags().IntVar(&scanLimit, "limit", 0, "maximum number of keys to return, 0 for no limit")
+	scanCmd.Flags().IntVar(&scanPageSize, "page-size", defaultPageSize, "number of keys to read per request")
+	scanCmd.Flags().BoolVar(&scanReverse, "reverse", false, "scan from the end of the range backwards, requires --end or --prefix")
+	scanCmd.Flags().StringVarP(&scanOutput, "output", "o", "jsonl", "output format: jsonl, tsv or csv")
+	scanCmd.Flags().BoolVar(&scanKeysOnly, "keys-only", false, "only output keys")
+	scanCmd.Flags().BoolVar(&scanCountOnly, "count-only", false, "only output the number of keys")
+	rootCmd.AddCommand(scanCmd)
+}
+
+// defaultPageSize 子命令分页扫描时每次读取的数量
+const defaultPageSize = 1000
+
+// maxPageSize 单次扫描的上限，TiKV 限制为 10240，分页时需要多读一条
+const maxPageSize = 10000
+
+// keyRange 子命令操作的key范围 [start, end)，end 为空表示扫描到最后
+type keyRange struct {
+	start []byte
+	end   []byte
+}
+
+// addRangeFlags 注册 --prefix/--start/--end/--key-encoding 参数
+func addRangeFlags(cmd *cobra.Command, prefix, start, end, encoding *string) {
+	cmd.Flags().StringVar(prefix, "prefix", "", "only include keys with this prefix")
+	cmd.Flags().StringVar(start, "start", "", "first key of the range (inclusive)")
+	cmd.Flags().StringVar(end, "end", "", "end of the range (exclusive), empty for no upper bound")
+	cmd.Flags().StringVar(encoding, "key-encoding", keyEncodingUTF8, "encoding of --prefix/--start/--end: utf8, hex or base64")
+}
+
+// parseRange 根据 --prefix 或 --start/--end 计算扫描范围
+func parseRange(prefix, start, end, encoding string) (keyRange, error) {
+	if prefix != "" {
+		if start != "" || end != "" {
+			return keyRange{}, fmt.Errorf("--prefix cannot be used together with --start or --end")
+		}
+		p, err := decodeKeyArg(prefix, encoding)
+		if err != nil {
+			return keyRange{}, err
+		}
+		return keyRange{start: p, end: dao.PrefixEnd(p)}, nil
+	}
+
+	var r keyRange
+	var err error
+	if r.start, err = decodeKeyArg(start, encoding); err != nil {
+		return keyRange{}, err
+	}
+	if end != "" {
+		if r.end, err = decodeKeyArg(end, encoding); err != nil {
+			return keyRange{}, err
+		}
+	}
+	return r, nil
+}
+
+// walkRange 按页扫描范围内的数据并依次回调 fn，limit 为 0 时不限制数量，返回扫描到的总数
+func walkRange(ctx context.Context, kv dao.KV, r keyRange, pageSize, limit int, keyOnly, reverse bool,
+	fn func(keys, vals [][]byte) error) (int, error) {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return 0, fmt.Errorf("invalid page size %d, must be between 1 and %d", pageSize, maxPageSize)
+	}
+	if reverse && len(r.end) == 0 {
+		return 0, fmt.Errorf("reverse scan requires an upper bound, use --end or --prefix")
+	}
+
+	start, end := r.start, r.end
+	total := 0
+	for limit <= 0 || total < limit {
+		size := pageSize
+		if limit > 0 && limit-total < size {
+			size = limit - total
+		}
+
+		var page *dao.Page
+		var err error
+		switch {
+		case reverse && keyOnly:
+			page, err = dao.KeyOnlyReverseScanPage(ctx, kv, start, end, size)
+		case reverse:
+			page, err = dao.ReverseScanPage(ctx, kv, start, end, size)
+		case keyOnly:
+			page, err = dao.KeyOnlyScanPage(ctx, kv, start, end, size)
+		default:
+			page, err = dao.ScanPage(ctx, kv, start, end, size)
+		}
+		if err != nil {
+			return total, fmt.Errorf("scan failed: %v", err)
+		}
+
+		if len(page.Keys) > 0 {
+			if err := fn(page.Keys, page.Vals); err != nil {
+				return total, err
+			}
+		}
+		total += len(page.Keys)
+
+		if !page.HasMore {
+			break
+		}
+		if reverse {
+			end = page.Next
+		} else {
+			start = page.Next
+		}
+	}
+
+	return total, nil
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	r, err := parseRange(scanPrefix, scanStart, scanEnd, scanKeyEncoding)
+	if err != nil {
+		return err
+	}
+	if scanReverse && len(r.end) == 0 {
+		return fmt.Errorf("--reverse requires an upper bound, use --end or --prefix")
+	}
+	switch strings.ToLower(scanOutput) {
+	case "jsonl", "tsv", "csv":
+	default:
+		return fmt.Errorf("unknown output format %q, expected jsonl, tsv or csv", scanOutput)
+	}
+
+	ctx := context.Background()
+	kv, err := openKv(ctx)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	if scanCountOnly {
+		total, err := walkRange(ctx, kv.KV, r, scanPageSize, scanLimit, true, scanReverse, func(keys, vals [][]byte) error {
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, total)
+		return nil
+	}
+
+	writer, err := newRecordWriter(out, scanOutput, scanKeysOnly)
+	if err != nil {
+		return err
+	}
+
+	_, err = walkRange(ctx, kv.KV, r, scanPageSize, scanLimit, scanKeysOnly, scanReverse, func(keys, vals [][]byte) error {
+		for i, key := range keys {
+			var val []byte
+			if !scanKeysOnly {
+				val = vals[i]
+			}
+			if err := writer.write(key, val); err != nil {
+				return err
+			}
+		}
+		// 每页输出一次，便于管道中的下游程序及时处理
+		return writer.flush()
+	})
+	return err
+}
+
+// recordWriter 按输出格式写入键值对，keysOnly 时只写key
+type recordWriter struct {
+	format   string
+	keysOnly bool
+	out      *bufio.Writer
+	json     *json.Encoder
+	csv      *csv.Writer
+}
+
+func newRecordWriter(out *bufio.Writer, format string, keysOnly bool) (*recordWriter, error) {
+	w := &recordWriter{format: strings.ToLower(format), keysOnly: keysOnly, out: out}
+	switch w.format {
+	case "jsonl":
+		w.json = json.NewEncoder(out)
+		w.json.SetEscapeHTML(false)
+	case "tsv":
+	case "csv":
+		w.csv = csv.NewWriter(out)
+		header := []string{"key", "value"}
+		if keysOnly {
+			header = header[:1]
+		}
+		if err := w.csv.Write(header); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected jsonl, tsv or csv", format)
+	}
+	return w, nil
+}
+
+func (w *recordWriter) write(key, val []byte) error {
+	switch w.format {
+	case "jsonl":
+		if w.keysOnly {
+			val = nil
+		} else if val == nil {
+			val = []byte{}
+		}
+		return w.json.Encode(dump.NewRecord(key, val))
+	case "csv":
+		record := []string{utils.Escape(key, false)}
+		if !w.keysOnly {
+			record = append(record, utils.Escape(val, false))
+		}
+		return w.csv.Write(record)
+	default:
+		line := utils.Escape(key, false)
+		if !w.keysOnly {
+			line += "\t" + utils.Escape(val, false)
+		}
+		_, err := io.WriteString(w.out, line+"\n")
+		return err
+	}
+}
+
+func (w *recordWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/baixiaoshi/tikvtool/client"
+	"github.com/baixiaoshi/tikvtool/dao"
+	"github.com/baixiaoshi/tikvtool/ui"
+)
+
+var errClusterClosed = errors.New("cluster connection has been closed")
+
+// session 管理交互界面中打开的集群连接，每个 profile 只连接一次，退出时统一关闭
+type session struct {
+	mu       sync.Mutex
+	config   *Config
+	profiles map[string]*Profile
+	clusters map[string]*sessionCluster
+}
+
+type sessionCluster struct {
+	cli        *client.RawKvClient
+	apiVersion string
+	keyspace   string
+	readOnly   bool
+	protected  []ui.ProtectedPrefix
+}
+
+// newSession 创建会话，name 对应的 profile 使用已合并命令行参数的配置
+func newSession(config *Config, name string, profile *Profile) *session {
+	return &session{
+		config:   config,
+		profiles: map[string]*Profile{name: profile},
+		clusters: map[string]*sessionCluster{},
+	}
+}
+
+// ProfileNames 可以切换的所有 profile
+func (s *session) ProfileNames() []string {
+	names := s.config.ProfileNames()
+	for name := range s.profiles {
+		found := false
+		for _, n := range names {
+			if n == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Connect 返回指定 profile 的集群，尚未连接时建立连接
+func (s *session) Connect(ctx context.Context, name string) (*ui.Cluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clusters[name]; ok {
+		return s.uiCluster(name, c), nil
+	}
+
+	profile, ok := s.profiles[name]
+	if !ok {
+		var err error
+		if _, profile, err = s.config.GetProfile(name); err != nil {
+			return nil, err
+		}
+		// --read-only 对会话中切换到的所有集群生效
+		if readOnly {
+			profile.ReadOnly = true
+		}
+		s.profiles[name] = profile
+	}
+
+	protected, err := profile.Protected()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %v", name, err)
+	}
+
+	cli, versionName, err := connectProfile(ctx, name, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &sessionCluster{
+		cli:        cli,
+		apiVersion: versionName,
+		keyspace:   profile.Keyspace,
+		readOnly:   profile.ReadOnly,
+		protected:  protected,
+	}
+	s.clusters[name] = c
+
+	return s.uiCluster(name, c), nil
+}
+
+// switchKeyspace 将指定集群的连接切换到另一个 keyspace，并关闭原连接
+func (s *session) switchKeyspace(name string) ui.KeyspaceSwitcher {
+	return func(ctx context.Context, keyspace string) (dao.KV, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		c, ok := s.clusters[name]
+		if !ok {
+			return nil, errClusterClosed
+		}
+
+		next, err := c.cli.WithKeyspace(ctx, keyspace)
+		if err != nil {
+			return nil, err
+		}
+		c.cli.Close()
+		c.cli = next
+		c.keyspace = keyspace
+
+		return c.kv(), nil
+	}
+}
+
+// kv 返回集群的数据访问对象，只读 profile 禁止写入和删除
+func (c *sessionCluster) kv() dao.KV {
+	if c.readOnly {
+		return dao.NewReadOnlyKv(dao.NewRawKv(c.cli))
+	}
+	return dao.NewRawKv(c.cli)
+}
+
+func (s *session) uiCluster(name string, c *sessionCluster) *ui.Cluster {
+	cluster := &ui.Cluster{
+		Name:       name,
+		ApiVersion: c.apiVersion,
+		Keyspace:   c.keyspace,
+		KV:         c.kv(),
+		Protected:  c.protected,
+	}
+	// 只有 API V2 支持 keyspace
+	if c.apiVersion == "V2" {
+		cluster.SwitchKeyspace = s.switchKeyspace(name)
+	}
+	return cluster
+}
+
+// Close 关闭所有打开的连接
+func (s *session) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, c := range s.clusters {
+		c.cli.Close()
+		delete(s.clusters, name)
+	}
+}
@@ -0,0 +1,126 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// KV 界面和子命令使用的存储接口，语义与 TiKV RawKV 一致：
+//   - Get 在key不存在时返回 nil，BatchGet 中不存在的key对应 nil
+//   - Scan 返回 [startKey, endKey) 中最多 limit 条数据，endKey 为空表示扫描到最后
+//   - ReverseScan 从 startKey（不包含）开始倒序扫描到 endKey（包含），startKey 不能为空
+//   - DeleteRange 删除 [startKey, endKey) 中的所有数据
+//
+// RawKv 连接真实的 TiKV 集群，MemKv 是用于演示和测试的内存实现
+type KV interface {
+	Get(ctx context.Context, key []byte) ([]byte, error)
+	BatchGet(ctx context.Context, keys [][]byte) ([][]byte, error)
+	Put(ctx context.Context, key, val []byte) error
+	BatchPut(ctx context.Context, keys, vals [][]byte) error
+	Delete(ctx context.Context, key []byte) error
+	DeleteRange(ctx context.Context, startKey, endKey []byte) error
+	Scan(ctx context.Context, startKey, endKey []byte, limit int) (keys [][]byte, vals [][]byte, err error)
+	ReverseScan(ctx context.Context, startKey, endKey []byte, limit int) (keys [][]byte, vals [][]byte, err error)
+}
+
+// KeyOnlyScanner 可以只扫描key而不读取value的存储，分页扫描时会优先使用
+type KeyOnlyScanner interface {
+	KeyOnlyScan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, error)
+	KeyOnlyReverseScan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, error)
+}
+
+// KeyspaceLister 可以列出 keyspace 的存储
+type KeyspaceLister interface {
+	ListKeyspaces(ctx context.Context) ([]Keyspace, error)
+}
+
+var (
+	_ KV             = (*RawKv)(nil)
+	_ KeyOnlyScanner = (*RawKv)(nil)
+	_ KeyspaceLister = (*RawKv)(nil)
+	_ KV             = (*MemKv)(nil)
+)
+
+// Page 分页扫描的一页结果
+type Page struct {
+	Keys [][]byte
+	Vals [][]byte
+	// HasMore 是否还有下一页，Next 为下一页的起始key（倒序扫描时为下一页的结束key）
+	HasMore bool
+	Next    []byte
+}
+
+// ScanPage 扫描 [startKey, endKey) 中最多 limit 条数据。多取一条用于判断是否还有下一页，
+// 下一页从本页最后一个key之后（lastKey + \x00）开始，可以用 Next 继续扫描
+func ScanPage(ctx context.Context, kv KV, startKey, endKey []byte, limit int) (*Page, error) {
+	return scanPage(ctx, kv, startKey, endKey, limit, false, false)
+}
+
+// KeyOnlyScanPage 与 ScanPage 相同，但只返回key，Page.Vals 为空
+func KeyOnlyScanPage(ctx context.Context, kv KV, startKey, endKey []byte, limit int) (*Page, error) {
+	return scanPage(ctx, kv, startKey, endKey, limit, true, false)
+}
+
+// ReverseScanPage 从 endKey（不包含）开始倒序扫描 [startKey, endKey) 中最多 limit 条数据，
+// 下一页以本页最后一个key作为 endKey 继续扫描。TiKV 不支持从末尾倒序扫描，endKey 不能为空
+func ReverseScanPage(ctx context.Context, kv KV, startKey, endKey []byte, limit int) (*Page, error) {
+	return scanPage(ctx, kv, startKey, endKey, limit, false, true)
+}
+
+// KeyOnlyReverseScanPage 与 ReverseScanPage 相同，但只返回key，Page.Vals 为空
+func KeyOnlyReverseScanPage(ctx context.Context, kv KV, startKey, endKey []byte, limit int) (*Page, error) {
+	return scanPage(ctx, kv, startKey, endKey, limit, true, true)
+}
+
+func scanPage(ctx context.Context, kv KV, startKey, endKey []byte, limit int, keyOnly, reverse bool) (*Page, error) {
+	if limit <= 0 {
+		return nil, errors.Errorf("invalid scan limit %d, must be positive", limit)
+	}
+	if reverse && len(endKey) == 0 {
+		return nil, errors.New("reverse scan requires an end key")
+	}
+
+	var keys, vals [][]byte
+	var err error
+	scanner, canKeyOnly := kv.(KeyOnlyScanner)
+	switch {
+	case keyOnly && canKeyOnly && reverse:
+		keys, err = scanner.KeyOnlyReverseScan(ctx, endKey, startKey, limit+1)
+	case keyOnly && canKeyOnly:
+		keys, err = scanner.KeyOnlyScan(ctx, startKey, endKey, limit+1)
+	case reverse:
+		keys, vals, err = kv.ReverseScan(ctx, endKey, startKey, limit+1)
+	default:
+		keys, vals, err = kv.Scan(ctx, startKey, endKey, limit+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if keyOnly {
+		vals = nil
+	}
+
+	page := &Page{Keys: keys, Vals: vals}
+	if len(keys) > limit {
+		page.Keys = keys[:limit]
+		if !keyOnly {
+			page.Vals = vals[:limit]
+		}
+		page.HasMore = true
+		if reverse {
+			page.Next = append([]byte(nil), page.Keys[limit-1]...)
+		} else {
+			page.Next = nextPageStart(page.Keys[limit-1])
+		}
+	}
+
+	return page, nil
+}
+
+// nextPageStart 返回紧跟在 lastKey 之后的最小key
+func nextPageStart(lastKey []byte) []byte {
+	next := make([]byte, len(lastKey)+1)
+	copy(next, lastKey)
+	return next
+}
@@ -0,0 +1,164 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/google/btree"
+	"github.com/pkg/errors"
+	"github.com/tikv/client-go/v2/rawkv"
+)
+
+// memItem 内存存储中的一个键值对
+type memItem struct {
+	key   []byte
+	value []byte
+}
+
+func memItemLess(a, b memItem) bool {
+	return bytes.Compare(a.key, b.key) < 0
+}
+
+// MemKv 基于 B 树的有序内存存储，实现与 TiKV RawKV 相同的读写语义，
+// 用于演示模式和离线测试。可以被多个 goroutine 同时使用
+type MemKv struct {
+	mu   sync.RWMutex
+	tree *btree.BTreeG[memItem]
+}
+
+// NewMemKv 创建一个空的内存存储
+func NewMemKv() *MemKv {
+	return &MemKv{tree: btree.NewG(32, memItemLess)}
+}
+
+// Len 返回存储中的键值对数量
+func (m *MemKv) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tree.Len()
+}
+
+func (m *MemKv) Get(ctx context.Context, key []byte) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.get(key), nil
+}
+
+func (m *MemKv) BatchGet(ctx context.Context, keys [][]byte) ([][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	vals := make([][]byte, len(keys))
+	for i, key := range keys {
+		vals[i] = m.get(key)
+	}
+	return vals, nil
+}
+
+func (m *MemKv) get(key []byte) []byte {
+	item, ok := m.tree.Get(memItem{key: key})
+	if !ok {
+		return nil
+	}
+	return cloneBytes(item.value)
+}
+
+func (m *MemKv) Put(ctx context.Context, key, val []byte) error {
+	return m.BatchPut(ctx, [][]byte{key}, [][]byte{val})
+}
+
+func (m *MemKv) BatchPut(ctx context.Context, keys, vals [][]byte) error {
+	if len(keys) != len(vals) {
+		return errors.New("the len of keys is not equal to the len of values")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, key := range keys {
+		m.tree.ReplaceOrInsert(memItem{key: cloneBytes(key), value: cloneBytes(vals[i])})
+	}
+	return nil
+}
+
+func (m *MemKv) Delete(ctx context.Context, key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tree.Delete(memItem{key: key})
+	return nil
+}
+
+// DeleteRange 删除 [startKey, endKey) 中的所有数据，endKey 为空表示删除到最后
+func (m *MemKv) DeleteRange(ctx context.Context, startKey, endKey []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var doomed []memItem
+	m.ascend(startKey, endKey, func(item memItem) bool {
+		doomed = append(doomed, item)
+		return true
+	})
+	for _, item := range doomed {
+		m.tree.Delete(item)
+	}
+	return nil
+}
+
+func (m *MemKv) Scan(ctx context.Context, startKey, endKey []byte, limit int) (keys [][]byte, vals [][]byte, err error) {
+	if limit > rawkv.MaxRawKVScanLimit {
+		return nil, nil, errors.WithStack(rawkv.ErrMaxScanLimitExceeded)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.ascend(startKey, endKey, func(item memItem) bool {
+		if len(keys) >= limit {
+			return false
+		}
+		keys = append(keys, cloneBytes(item.key))
+		vals = append(vals, cloneBytes(item.value))
+		return true
+	})
+	return keys, vals, nil
+}
+
+// ReverseScan 从 startKey（不包含）开始倒序扫描到 endKey（包含）。
+// 与 TiKV 一致，startKey 不能为空
+func (m *MemKv) ReverseScan(ctx context.Context, startKey, endKey []byte, limit int) (keys [][]byte, vals [][]byte, err error) {
+	if limit > rawkv.MaxRawKVScanLimit {
+		return nil, nil, errors.WithStack(rawkv.ErrMaxScanLimitExceeded)
+	}
+	if len(startKey) == 0 {
+		return nil, nil, errors.New("reverse scan from an empty key is not supported")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.tree.DescendLessOrEqual(memItem{key: startKey}, func(item memItem) bool {
+		if bytes.Equal(item.key, startKey) {
+			return true
+		}
+		if bytes.Compare(item.key, endKey) < 0 || len(keys) >= limit {
+			return false
+		}
+		keys = append(keys, cloneBytes(item.key))
+		vals = append(vals, cloneBytes(item.value))
+		return true
+	})
+	return keys, vals, nil
+}
+
+// ascend 按顺序遍历 [startKey, endKey) 中的数据，endKey 为空表示遍历到最后
+func (m *MemKv) ascend(startKey, endKey []byte, fn func(item memItem) bool) {
+	if len(endKey) == 0 {
+		m.tree.AscendGreaterOrEqual(memItem{key: startKey}, fn)
+		return
+	}
+	m.tree.AscendRange(memItem{key: startKey}, memItem{key: endKey}, fn)
+}
+
+// cloneBytes 复制数据，避免调用方修改存储中的内容。nil 复制为空切片，
+// 与 TiKV 一样区分空值和不存在
+func cloneBytes(b []byte) []byte {
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,214 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func newTestMemKv(t *testing.T, keys ...string) *MemKv {
+	t.Helper()
+	kv := NewMemKv()
+	for _, key := range keys {
+		if err := kv.Put(context.Background(), []byte(key), []byte("v-"+key)); err != nil {
+			t.Fatalf("Put(%q) error: %v", key, err)
+		}
+	}
+	return kv
+}
+
+func toStrings(keys [][]byte) []string {
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = string(key)
+	}
+	return out
+}
+
+func TestMemKvGetPutDelete(t *testing.T) {
+	ctx := context.Background()
+	kv := newTestMemKv(t, "a", "b")
+
+	if v, _ := kv.Get(ctx, []byte("a")); string(v) != "v-a" {
+		t.Fatalf("Get(a) = %q, want v-a", v)
+	}
+	if v, _ := kv.Get(ctx, []byte("missing")); v != nil {
+		t.Fatalf("Get(missing) = %q, want nil", v)
+	}
+
+	// 空值与不存在需要区分
+	if err := kv.Put(ctx, []byte("empty"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := kv.Get(ctx, []byte("empty")); v == nil || len(v) != 0 {
+		t.Fatalf("Get(empty) = %#v, want empty non-nil value", v)
+	}
+
+	vals, _ := kv.BatchGet(ctx, [][]byte{[]byte("b"), []byte("missing")})
+	if string(vals[0]) != "v-b" || vals[1] != nil {
+		t.Fatalf("BatchGet = %q, want [v-b nil]", vals)
+	}
+
+	if err := kv.Delete(ctx, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := kv.Get(ctx, []byte("a")); v != nil {
+		t.Fatalf("Get(a) after delete = %q, want nil", v)
+	}
+}
+
+func TestMemKvCopiesData(t *testing.T) {
+	ctx := context.Background()
+	kv := NewMemKv()
+	key, value := []byte("k"), []byte("value")
+	kv.Put(ctx, key, value)
+	value[0] = 'X'
+
+	got, _ := kv.Get(ctx, key)
+	if string(got) != "value" {
+		t.Fatalf("stored value changed through the caller's slice: %q", got)
+	}
+	got[0] = 'Y'
+	if again, _ := kv.Get(ctx, key); string(again) != "value" {
+		t.Fatalf("stored value changed through the returned slice: %q", again)
+	}
+}
+
+func TestMemKvScan(t *testing.T) {
+	ctx := context.Background()
+	kv := newTestMemKv(t, "a", "b", "b\x00", "c", "d")
+
+	tests := []struct {
+		name       string
+		reverse    bool
+		start, end string
+		limit      int
+		want       []string
+	}{
+		{name: "range", start: "b", end: "d", limit: 10, want: []string{"b", "b\x00", "c"}},
+		{name: "open end", start: "c", limit: 10, want: []string{"c", "d"}},
+		{name: "limit", start: "", limit: 2, want: []string{"a", "b"}},
+		{name: "reverse", reverse: true, start: "d", end: "b", limit: 10, want: []string{"c", "b\x00", "b"}},
+		{name: "reverse to beginning", reverse: true, start: "b", limit: 10, want: []string{"a"}},
+		{name: "reverse limit", reverse: true, start: "z", end: "a", limit: 2, want: []string{"d", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := kv.Scan
+			if tt.reverse {
+				scan = kv.ReverseScan
+			}
+			keys, vals, err := scan(ctx, []byte(tt.start), []byte(tt.end), tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := toStrings(keys); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("keys = %q, want %q", got, tt.want)
+			}
+			for i, key := range keys {
+				if string(vals[i]) != "v-"+string(key) {
+					t.Fatalf("value of %q = %q", key, vals[i])
+				}
+			}
+		})
+	}
+
+	if _, _, err := kv.ReverseScan(ctx, nil, nil, 10); err == nil {
+		t.Fatal("ReverseScan from an empty key should fail")
+	}
+}
+
+func TestMemKvDeleteRange(t *testing.T) {
+	ctx := context.Background()
+	kv := newTestMemKv(t, "a", "b", "b1", "c", "d")
+
+	if err := kv.DeleteRange(ctx, []byte("b"), []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	keys, _, _ := kv.Scan(ctx, nil, nil, 10)
+	if got, want := toStrings(keys), []string{"a", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys after DeleteRange = %q, want %q", got, want)
+	}
+
+	if err := kv.DeleteRange(ctx, []byte("c"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if kv.Len() != 1 {
+		t.Fatalf("Len() = %d after deleting to the end, want 1", kv.Len())
+	}
+}
+
+func TestScanPageOverMemKv(t *testing.T) {
+	ctx := context.Background()
+	kv := NewMemKv()
+	for i := 0; i < 25; i++ {
+		kv.Put(ctx, []byte(fmt.Sprintf("user/%02d", i)), []byte("x"))
+	}
+	kv.Put(ctx, []byte("other"), []byte("x"))
+
+	prefix := []byte("user/")
+	end := PrefixEnd(prefix)
+
+	var forward []string
+	start := prefix
+	for pages := 0; ; pages++ {
+		page, err := KeyOnlyScanPage(ctx, kv, start, end, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Vals != nil {
+			t.Fatal("key-only page returned values")
+		}
+		forward = append(forward, toStrings(page.Keys)...)
+		if !page.HasMore {
+			if pages != 2 {
+				t.Fatalf("got %d pages, want 3", pages+1)
+			}
+			break
+		}
+		start = page.Next
+	}
+
+	var reverse []string
+	upper := end
+	for {
+		page, err := ReverseScanPage(ctx, kv, prefix, upper, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reverse = append(reverse, toStrings(page.Keys)...)
+		if !page.HasMore {
+			break
+		}
+		upper = page.Next
+	}
+
+	if len(forward) != 25 || len(reverse) != 25 {
+		t.Fatalf("forward %d keys, reverse %d keys, want 25", len(forward), len(reverse))
+	}
+	for i := range forward {
+		if forward[i] != reverse[len(reverse)-1-i] {
+			t.Fatalf("reverse pages do not mirror forward pages at %d: %q vs %q", i, forward[i], reverse[len(reverse)-1-i])
+		}
+	}
+}
+
+func TestScanPageInvalidLimit(t *testing.T) {
+	ctx := context.Background()
+	kv := newTestMemKv(t, "a", "b")
+	scans := map[string]func(context.Context, KV, []byte, []byte, int) (*Page, error){
+		"ScanPage":               ScanPage,
+		"KeyOnlyScanPage":        KeyOnlyScanPage,
+		"ReverseScanPage":        ReverseScanPage,
+		"KeyOnlyReverseScanPage": KeyOnlyReverseScanPage,
+	}
+	for name, scan := range scans {
+		for _, limit := range []int{0, -1} {
+			if _, err := scan(ctx, kv, []byte("a"), []byte("z"), limit); err == nil {
+				t.Errorf("%s with limit %d succeeded", name, limit)
+			}
+		}
+	}
+}
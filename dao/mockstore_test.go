@@ -0,0 +1,309 @@
+package dao_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/baixiaoshi/tikvtool/client/mockstore"
+	"github.com/baixiaoshi/tikvtool/dao"
+)
+
+func putKeys(t *testing.T, kv dao.KV, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		if err := kv.Put(context.Background(), []byte(key), []byte("v-"+key)); err != nil {
+			t.Fatalf("Put(%q) error: %v", key, err)
+		}
+	}
+}
+
+func keyStrings(keys [][]byte) []string {
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = string(key)
+	}
+	return out
+}
+
+// newSplitCluster 启动按 user/5 和 v 切分为三个 region 的 mock 集群，覆盖跨 region 的请求
+func newSplitCluster(t *testing.T) *mockstore.Cluster {
+	return mockstore.NewCluster(t, []byte("user/5"), []byte("v"))
+}
+
+func TestRawKvGetPutDelete(t *testing.T) {
+	ctx := context.Background()
+	kv := newSplitCluster(t).NewV1RawKv(t)
+	putKeys(t, kv, "user/1", "user/7", "zzz")
+
+	if v, err := kv.Get(ctx, []byte("user/7")); err != nil || string(v) != "v-user/7" {
+		t.Fatalf("Get(user/7) = %q, %v", v, err)
+	}
+	if v, err := kv.Get(ctx, []byte("missing")); err != nil || v != nil {
+		t.Fatalf("Get(missing) = %q, %v, want nil", v, err)
+	}
+
+	vals, err := kv.BatchGet(ctx, [][]byte{[]byte("user/1"), []byte("missing"), []byte("zzz")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TiKV 对不存在的key返回 nil，mock 集群会返回空值，这里只检查没有数据
+	if string(vals[0]) != "v-user/1" || len(vals[1]) != 0 || string(vals[2]) != "v-zzz" {
+		t.Fatalf("BatchGet = %q", vals)
+	}
+
+	if err := kv.Delete(ctx, []byte("user/1")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := kv.Get(ctx, []byte("user/1")); v != nil {
+		t.Fatalf("Get(user/1) after delete = %q", v)
+	}
+}
+
+func TestRawKvPrefixScan(t *testing.T) {
+	ctx := context.Background()
+	kv := newSplitCluster(t).NewV1RawKv(t)
+	putKeys(t, kv, "use", "user", "user/1", "user/4", "user/5", "user/9", "user0", "userx", "v1")
+
+	keys, vals, err := kv.PrefixScan(ctx, []byte("user/"), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"user/1", "user/4", "user/5", "user/9"}
+	if got := keyStrings(keys); !reflect.DeepEqual(got, want) {
+		t.Fatalf("PrefixScan(user/) = %q, want %q", got, want)
+	}
+	for i, key := range keys {
+		if string(vals[i]) != "v-"+string(key) {
+			t.Fatalf("value of %q = %q", key, vals[i])
+		}
+	}
+
+	keys, err = kv.KeyOnlyScan(ctx, []byte("user"), dao.PrefixEnd([]byte("user")), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"user", "user/1", "user/4", "user/5", "user/9", "user0", "userx"}
+	if got := keyStrings(keys); !reflect.DeepEqual(got, want) {
+		t.Fatalf("KeyOnlyScan(user) = %q, want %q", got, want)
+	}
+}
+
+func TestRawKvPrefixScanBinary(t *testing.T) {
+	ctx := context.Background()
+	kv := mockstore.NewCluster(t).NewV1RawKv(t)
+	prefix := []byte{0x01, 0xFF}
+	keys := [][]byte{{0x01, 0xFE, 0xFF}, {0x01, 0xFF}, {0x01, 0xFF, 0x00}, {0x01, 0xFF, 0xFF, 0xFF}, {0x02}}
+	for _, key := range keys {
+		if err := kv.Put(ctx, key, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, _, err := kv.PrefixScan(ctx, prefix, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !bytes.Equal(got[0], keys[1]) || !bytes.Equal(got[2], keys[3]) {
+		t.Fatalf("PrefixScan(%x) = %x", prefix, got)
+	}
+}
+
+func TestRawKvDeleteRange(t *testing.T) {
+	ctx := context.Background()
+	kv := newSplitCluster(t).NewV1RawKv(t)
+	putKeys(t, kv, "user", "user/1", "user/5", "user/9", "user0", "v1")
+
+	prefix := []byte("user/")
+	if err := kv.DeleteRange(ctx, prefix, dao.PrefixEnd(prefix)); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _, err := kv.Scan(ctx, nil, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"user", "user0", "v1"}
+	if got := keyStrings(keys); !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys after DeleteRange(user/) = %q, want %q", got, want)
+	}
+}
+
+func TestRawKvPagination(t *testing.T) {
+	ctx := context.Background()
+	kv := newSplitCluster(t).NewV1RawKv(t)
+
+	var all []string
+	var keys, vals [][]byte
+	for i := 0; i < 250; i++ {
+		key := fmt.Sprintf("user/%03d", i)
+		all = append(all, key)
+		keys = append(keys, []byte(key))
+		vals = append(vals, []byte(fmt.Sprintf("value %d", i)))
+	}
+	if err := kv.BatchPut(ctx, keys, vals); err != nil {
+		t.Fatal(err)
+	}
+	putKeys(t, kv, "user.", "user0")
+
+	prefix := []byte("user/")
+	end := dao.PrefixEnd(prefix)
+
+	// 正序分页：100 + 100 + 50
+	var forward []string
+	var sizes []int
+	start := prefix
+	for {
+		page, err := dao.ScanPage(ctx, kv, start, end, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, key := range page.Keys {
+			if len(page.Vals[i]) == 0 {
+				t.Fatalf("page value of %q is empty", key)
+			}
+		}
+		forward = append(forward, keyStrings(page.Keys)...)
+		sizes = append(sizes, len(page.Keys))
+		if !page.HasMore {
+			break
+		}
+		start = page.Next
+	}
+	if !reflect.DeepEqual(sizes, []int{100, 100, 50}) {
+		t.Fatalf("page sizes = %v, want [100 100 50]", sizes)
+	}
+	if !reflect.DeepEqual(forward, all) {
+		t.Fatalf("forward pages returned %d keys, want the 250 keys in order", len(forward))
+	}
+
+	// 倒序只取 key，结果应与正序相反
+	var reverse []string
+	upper := end
+	for {
+		page, err := dao.KeyOnlyReverseScanPage(ctx, kv, prefix, upper, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Vals != nil {
+			t.Fatal("key-only page returned values")
+		}
+		reverse = append(reverse, keyStrings(page.Keys)...)
+		if !page.HasMore {
+			break
+		}
+		upper = page.Next
+	}
+	if len(reverse) != len(all) {
+		t.Fatalf("reverse pages returned %d keys, want %d", len(reverse), len(all))
+	}
+	for i := range all {
+		if reverse[len(reverse)-1-i] != all[i] {
+			t.Fatalf("reverse key %d = %q, want %q", len(reverse)-1-i, reverse[len(reverse)-1-i], all[i])
+		}
+	}
+
+	// 恰好一整页时不应报告还有下一页
+	page, err := dao.ScanPage(ctx, kv, []byte("user/200"), end, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Keys) != 50 || page.HasMore {
+		t.Fatalf("last full page: %d keys, HasMore=%v", len(page.Keys), page.HasMore)
+	}
+}
+
+func TestRawKvAPIV2Keyspaces(t *testing.T) {
+	ctx := context.Background()
+	cluster := mockstore.NewCluster(t)
+	cluster.AddKeyspace(2, "orders")
+	cluster.AddKeyspace(1, "users")
+
+	users := cluster.NewV2RawKv(t, "users")
+	orders := cluster.NewV2RawKv(t, "orders")
+	putKeys(t, users, "a", "b")
+	if err := orders.Put(ctx, []byte("a"), []byte("order")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同名key在不同 keyspace 中互不影响
+	if v, _ := users.Get(ctx, []byte("a")); string(v) != "v-a" {
+		t.Fatalf("users: Get(a) = %q", v)
+	}
+	if v, _ := orders.Get(ctx, []byte("a")); string(v) != "order" {
+		t.Fatalf("orders: Get(a) = %q", v)
+	}
+	keys, _, err := orders.Scan(ctx, nil, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := keyStrings(keys); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("orders: Scan = %q, want only its own key", got)
+	}
+
+	// 不编码的 V1 客户端可以看到 API V2 实际写入的key：'r' + 3 字节 keyspace ID + key
+	raw := cluster.NewV1RawKv(t)
+	keys, _, err = raw.Scan(ctx, nil, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"r\x00\x00\x01a", "r\x00\x00\x01b", "r\x00\x00\x02a"}
+	if got := keyStrings(keys); !reflect.DeepEqual(got, want) {
+		t.Fatalf("encoded keys = %q, want %q", got, want)
+	}
+
+	// DeleteRange 只删除当前 keyspace 中的数据
+	if err := users.DeleteRange(ctx, []byte("a"), []byte("z")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := orders.Get(ctx, []byte("a")); string(v) != "order" {
+		t.Fatalf("orders: Get(a) after deleting users = %q", v)
+	}
+
+	keyspaces, err := users.ListKeyspaces(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKeyspaces := []dao.Keyspace{
+		{ID: 1, Name: "users", State: "ENABLED"},
+		{ID: 2, Name: "orders", State: "ENABLED"},
+	}
+	if !reflect.DeepEqual(keyspaces, wantKeyspaces) {
+		t.Fatalf("ListKeyspaces = %+v, want %+v", keyspaces, wantKeyspaces)
+	}
+}
+
+// TestMemKvMatchesRawKv 对内存存储和 mock 集群执行相同的操作，结果应该一致
+func TestMemKvMatchesRawKv(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]dao.KV{
+		"mem":  dao.NewMemKv(),
+		"tikv": newSplitCluster(t).NewV1RawKv(t),
+	}
+
+	results := map[string][]string{}
+	for name, kv := range stores {
+		putKeys(t, kv, "a", "user", "user/1", "user/5", "user/9", "userx", "v", "w")
+		if err := kv.DeleteRange(ctx, []byte("user/5"), []byte("userx")); err != nil {
+			t.Fatal(err)
+		}
+		if err := kv.Delete(ctx, []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+		keys, _, err := kv.Scan(ctx, []byte("b"), nil, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reverse, _, err := kv.ReverseScan(ctx, []byte("w"), []byte("user"), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[name] = append(keyStrings(keys), keyStrings(reverse)...)
+	}
+
+	if !reflect.DeepEqual(results["mem"], results["tikv"]) {
+		t.Fatalf("MemKv returned %q, RawKv returned %q", results["mem"], results["tikv"])
+	}
+}
@@ -2,22 +2,45 @@ package dao
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/baixiaoshi/tikvtool/client"
 
+	"github.com/pkg/errors"
 	"github.com/tikv/client-go/v2/rawkv"
 )
 
+// Keyspace PD 中登记的 keyspace 信息
+type Keyspace struct {
+	ID    uint32
+	Name  string
+	State string
+}
+
+// RawKv 通过 client-go RawKV 访问 TiKV 集群
 type RawKv struct {
-	cli *rawkv.Client
+	client *client.RawKvClient
+	cli    *rawkv.Client
 }
 
-func NewRawKv() *RawKv {
+func NewRawKv(cli *client.RawKvClient) *RawKv {
 	return &RawKv{
-		cli: client.RawKVClient,
+		client: cli,
+		cli:    cli.Raw(),
 	}
 }
 
+// Close 关闭底层的集群连接
+func (c *RawKv) Close() error {
+	return c.client.Close()
+}
+
+// ClusterID 返回集群ID
+func (c *RawKv) ClusterID() uint64 {
+	return c.cli.ClusterID()
+}
+
 func (c *RawKv) Get(ctx context.Context, key []byte) ([]byte, error) {
 	return c.cli.Get(ctx, key)
 }
@@ -32,6 +55,11 @@ func (c *RawKv) Put(ctx context.Context, key, val []byte) error {
 	return c.cli.Put(ctx, key, val)
 }
 
+// PutWithTTL 写入带过期时间的键值对，ttl 单位为秒，需要集群启用 TTL（API V1TTL 或 V2）
+func (c *RawKv) PutWithTTL(ctx context.Context, key, val []byte, ttl uint64) error {
+	return c.cli.PutWithTTL(ctx, key, val, ttl)
+}
+
 func (c *RawKv) BatchPut(ctx context.Context, keys, vals [][]byte) error {
 
 	return c.cli.BatchPut(ctx, keys, vals)
@@ -63,7 +91,7 @@ func (c *RawKv) ReverseScan(ctx context.Context, startKey, endKey []byte, limit
 // PrefixScan 前缀扫描 - 用于查询去除mfymos_前缀后的子前缀
 func (c *RawKv) PrefixScan(ctx context.Context, prefix []byte, limit int) (keys [][]byte, vals [][]byte, err error) {
 	startKey := prefix
-	endKey := nextKey(prefix)
+	endKey := PrefixEnd(prefix)
 
 	keys, vals, err = c.cli.Scan(ctx, startKey, endKey, limit)
 
@@ -73,12 +101,24 @@ func (c *RawKv) PrefixScan(ctx context.Context, prefix []byte, limit int) (keys
 // ScanWithRealPrefix 使用实际前缀扫描 - 直接使用用户输入的前缀，不添加任何前缀
 func (c *RawKv) ScanWithRealPrefix(ctx context.Context, userPrefix []byte, limit int) (keys [][]byte, vals [][]byte, err error) {
 	startKey := userPrefix
-	endKey := nextKey(userPrefix)
+	endKey := PrefixEnd(userPrefix)
 
 	keys, vals, err = c.cli.Scan(ctx, startKey, endKey, limit)
 	return
 }
 
+// KeyOnlyScan 只扫描 [startKey, endKey) 中的key，不读取value
+func (c *RawKv) KeyOnlyScan(ctx context.Context, startKey, endKey []byte, limit int) (keys [][]byte, err error) {
+	keys, _, err = c.cli.Scan(ctx, startKey, endKey, limit, rawkv.ScanKeyOnly())
+	return
+}
+
+// KeyOnlyReverseScan 从 startKey（不包含）开始倒序扫描到 endKey（包含），只返回key
+func (c *RawKv) KeyOnlyReverseScan(ctx context.Context, startKey, endKey []byte, limit int) (keys [][]byte, err error) {
+	keys, _, err = c.cli.ReverseScan(ctx, startKey, endKey, limit, rawkv.ScanKeyOnly())
+	return
+}
+
 // ScanAllKeys 扫描所有key（不限制前缀）
 func (c *RawKv) ScanAllKeys(ctx context.Context, limit int) (keys [][]byte, vals [][]byte, err error) {
 	// TiKV: 当 endKey 为 nil 时，扫描到最后
@@ -86,7 +126,50 @@ func (c *RawKv) ScanAllKeys(ctx context.Context, limit int) (keys [][]byte, vals
 	return
 }
 
-// nextKey 生成下一个key，用于范围查询 - 使用简单的追加0xFF方法
-func nextKey(startKey []byte) []byte {
-	return append(startKey, 0xFF)
+// ListKeyspaces 从 PD 获取所有 keyspace（仅 API V2 集群支持）
+func (c *RawKv) ListKeyspaces(ctx context.Context) ([]Keyspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// WatchKeyspaces 返回的第一条消息包含当前所有 keyspace
+	ch, err := c.cli.GetPDClient().WatchKeyspaces(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "watch keyspaces")
+	}
+
+	select {
+	case metas, ok := <-ch:
+		if !ok {
+			return nil, errors.New("keyspace watcher closed unexpectedly")
+		}
+		keyspaces := make([]Keyspace, 0, len(metas))
+		for _, meta := range metas {
+			keyspaces = append(keyspaces, Keyspace{
+				ID:    meta.GetId(),
+				Name:  meta.GetName(),
+				State: meta.GetState().String(),
+			})
+		}
+		sort.Slice(keyspaces, func(i, j int) bool {
+			return keyspaces[i].ID < keyspaces[j].ID
+		})
+		return keyspaces, nil
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "load keyspaces from PD")
+	}
+}
+
+// PrefixEnd 计算前缀扫描的结束key（不包含），即大于所有以 prefix 开头的key的最小key：
+// 去掉末尾的 0xFF 后将最后一个字节加一。prefix 为空或全部为 0xFF 时返回 nil，表示扫描到最后。
+// 返回新分配的切片，不会修改 prefix
+func PrefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+	return nil
 }
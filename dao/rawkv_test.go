@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix []byte
+		want   []byte
+	}{
+		{name: "nil", prefix: nil, want: nil},
+		{name: "empty", prefix: []byte{}, want: nil},
+		{name: "ascii", prefix: []byte("abc"), want: []byte("abd")},
+		{name: "single byte", prefix: []byte{0x00}, want: []byte{0x01}},
+		{name: "binary", prefix: []byte{0x74, 0x80, 0x00, 0x01}, want: []byte{0x74, 0x80, 0x00, 0x02}},
+		{name: "trailing 0xFF", prefix: []byte("abc\xff"), want: []byte("abd")},
+		{name: "multiple trailing 0xFF", prefix: []byte{0x01, 0xFE, 0xFF, 0xFF}, want: []byte{0x01, 0xFF}},
+		{name: "all 0xFF", prefix: []byte{0xFF, 0xFF}, want: nil},
+		{name: "single 0xFF", prefix: []byte{0xFF}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PrefixEnd(tt.prefix)
+			if !bytes.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Fatalf("PrefixEnd(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixEndCoversPrefixedKeys(t *testing.T) {
+	prefixes := [][]byte{
+		[]byte("abc"),
+		[]byte("abc\xff"),
+		{0x00},
+		{0x01, 0xFF},
+	}
+	suffixes := [][]byte{
+		nil,
+		{0x00},
+		{0x01},
+		{0xFF},
+		{0xFF, 0x01},
+		{0xFF, 0xFF, 0xFF},
+	}
+
+	for _, prefix := range prefixes {
+		end := PrefixEnd(prefix)
+		for _, suffix := range suffixes {
+			key := append(append([]byte{}, prefix...), suffix...)
+			if end != nil && bytes.Compare(key, end) >= 0 {
+				t.Errorf("key %q with prefix %q is not below end %q", key, prefix, end)
+			}
+		}
+		// 结束key本身不能以 prefix 开头
+		if end != nil && bytes.HasPrefix(end, prefix) {
+			t.Errorf("end %q of prefix %q still has the prefix", end, prefix)
+		}
+	}
+}
+
+func TestPrefixEndDoesNotModifyInput(t *testing.T) {
+	buf := make([]byte, 3, 16)
+	copy(buf, "abc")
+	spare := buf[:4]
+	spare[3] = 'x'
+
+	end := PrefixEnd(buf)
+	end[len(end)-1] = 'z'
+
+	if string(buf) != "abc" || spare[3] != 'x' {
+		t.Fatalf("PrefixEnd modified its input: %q %q", buf, spare)
+	}
+}
+
+func TestNextPageStart(t *testing.T) {
+	last := []byte("abc\xff")
+	next := nextPageStart(last)
+
+	if !bytes.Equal(next, []byte("abc\xff\x00")) {
+		t.Fatalf("nextPageStart(%q) = %q", last, next)
+	}
+	if bytes.Compare(next, last) <= 0 {
+		t.Fatalf("next page start %q is not after %q", next, last)
+	}
+	if &next[0] == &last[0] {
+		t.Fatalf("nextPageStart must not share the buffer of its input")
+	}
+}
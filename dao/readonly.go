@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrReadOnly 只读模式下写入或删除数据时返回的错误
+var ErrReadOnly = errors.New("read-only mode: writes and deletes are disabled")
+
+// ReadOnlyKv 包装一个存储，读取和扫描直接转发，Put、BatchPut、Delete 和 DeleteRange
+// 一律返回 ErrReadOnly。被包装的存储支持的可选接口（KeyOnlyScanner、KeyspaceLister）保持可用
+type ReadOnlyKv struct {
+	kv KV
+}
+
+var (
+	_ KV             = (*ReadOnlyKv)(nil)
+	_ KeyOnlyScanner = (*ReadOnlyKv)(nil)
+	_ KeyspaceLister = (*ReadOnlyKv)(nil)
+)
+
+// NewReadOnlyKv 返回 kv 的只读视图，kv 已经是只读时直接返回
+func NewReadOnlyKv(kv KV) *ReadOnlyKv {
+	if r, ok := kv.(*ReadOnlyKv); ok {
+		return r
+	}
+	return &ReadOnlyKv{kv: kv}
+}
+
+// IsReadOnly 判断存储是否为只读
+func IsReadOnly(kv KV) bool {
+	_, ok := kv.(*ReadOnlyKv)
+	return ok
+}
+
+func (r *ReadOnlyKv) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return r.kv.Get(ctx, key)
+}
+
+func (r *ReadOnlyKv) BatchGet(ctx context.Context, keys [][]byte) ([][]byte, error) {
+	return r.kv.BatchGet(ctx, keys)
+}
+
+func (r *ReadOnlyKv) Put(ctx context.Context, key, val []byte) error {
+	return ErrReadOnly
+}
+
+func (r *ReadOnlyKv) BatchPut(ctx context.Context, keys, vals [][]byte) error {
+	return ErrReadOnly
+}
+
+func (r *ReadOnlyKv) Delete(ctx context.Context, key []byte) error {
+	return ErrReadOnly
+}
+
+func (r *ReadOnlyKv) DeleteRange(ctx context.Context, startKey, endKey []byte) error {
+	return ErrReadOnly
+}
+
+func (r *ReadOnlyKv) Scan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	return r.kv.Scan(ctx, startKey, endKey, limit)
+}
+
+func (r *ReadOnlyKv) ReverseScan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	return r.kv.ReverseScan(ctx, startKey, endKey, limit)
+}
+
+// KeyOnlyScan 被包装的存储不支持只扫描key时，扫描完整数据并丢弃 value
+func (r *ReadOnlyKv) KeyOnlyScan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, error) {
+	if scanner, ok := r.kv.(KeyOnlyScanner); ok {
+		return scanner.KeyOnlyScan(ctx, startKey, endKey, limit)
+	}
+	keys, _, err := r.kv.Scan(ctx, startKey, endKey, limit)
+	return keys, err
+}
+
+// KeyOnlyReverseScan 与 KeyOnlyScan 相同，倒序扫描
+func (r *ReadOnlyKv) KeyOnlyReverseScan(ctx context.Context, startKey, endKey []byte, limit int) ([][]byte, error) {
+	if scanner, ok := r.kv.(KeyOnlyScanner); ok {
+		return scanner.KeyOnlyReverseScan(ctx, startKey, endKey, limit)
+	}
+	keys, _, err := r.kv.ReverseScan(ctx, startKey, endKey, limit)
+	return keys, err
+}
+
+// ListKeyspaces 转发给被包装的存储，不支持时返回错误
+func (r *ReadOnlyKv) ListKeyspaces(ctx context.Context) ([]Keyspace, error) {
+	lister, ok := r.kv.(KeyspaceLister)
+	if !ok {
+		return nil, errors.New("listing keyspaces is not supported by this storage")
+	}
+	return lister.ListKeyspaces(ctx)
+}
@@ -0,0 +1,55 @@
+package dao
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestReadOnlyKvRejectsWrites(t *testing.T) {
+	ctx := context.Background()
+	mem := newTestMemKv(t, "a", "b")
+	kv := NewReadOnlyKv(mem)
+
+	writes := map[string]error{
+		"Put":         kv.Put(ctx, []byte("c"), []byte("v")),
+		"BatchPut":    kv.BatchPut(ctx, [][]byte{[]byte("c")}, [][]byte{[]byte("v")}),
+		"Delete":      kv.Delete(ctx, []byte("a")),
+		"DeleteRange": kv.DeleteRange(ctx, []byte("a"), nil),
+	}
+	for name, err := range writes {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s error = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if mem.Len() != 2 {
+		t.Fatalf("underlying store has %d keys after rejected writes, want 2", mem.Len())
+	}
+
+	if v, err := kv.Get(ctx, []byte("a")); err != nil || string(v) != "v-a" {
+		t.Fatalf("Get(a) = %q, %v", v, err)
+	}
+	page, err := KeyOnlyScanPage(ctx, kv, nil, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := toStrings(page.Keys); !reflect.DeepEqual(got, []string{"a", "b"}) || page.Vals != nil {
+		t.Fatalf("KeyOnlyScanPage = %q, %q", got, page.Vals)
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	mem := NewMemKv()
+	if IsReadOnly(mem) {
+		t.Fatal("MemKv reported as read-only")
+	}
+	kv := NewReadOnlyKv(mem)
+	if !IsReadOnly(kv) {
+		t.Fatal("ReadOnlyKv not reported as read-only")
+	}
+	if NewReadOnlyKv(kv) != kv {
+		t.Fatal("wrapping a read-only store twice should return it unchanged")
+	}
+}
@@ -0,0 +1,157 @@
+// Package dump 实现导出文件的格式：JSON Lines 和带长度前缀的二进制格式，可选 gzip 或 zstd 压缩。
+//
+// 两种格式都以包含集群和范围信息的头部开始，以记录总数的尾部结束，读取时可以发现被截断的文件。
+// JSON Lines 格式的每一行是一个 JSON 对象：
+//
+//	{"format":"tikvtool-dump","version":1,"cluster_id":...,"start":"user/","end":"user0",...}
+//	{"key":"user/1","value":"..."}
+//	{"end":true,"count":1}
+//
+// 二进制格式为 "TIKVDUMP" 和一个字节的版本号，之后是 uvarint 长度前缀的头部 JSON，
+// 每条记录为 'R'、uvarint 长度前缀的key和value，最后是 'E' 和 uvarint 编码的记录总数。
+package dump
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatName 头部中标识导出文件的名称
+	FormatName = "tikvtool-dump"
+	// Version 当前的文件格式版本
+	Version = 1
+)
+
+// binaryMagic 二进制格式的文件头
+const binaryMagic = "TIKVDUMP"
+
+// 二进制格式中的记录类型
+const (
+	tagRecord = 'R'
+	tagEnd    = 'E'
+)
+
+// Format 导出文件的格式
+type Format string
+
+const (
+	FormatJSONL  Format = "jsonl"
+	FormatBinary Format = "binary"
+)
+
+// ParseFormat 解析命令行中的格式名
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatJSONL, "json":
+		return FormatJSONL, nil
+	case FormatBinary, "bin":
+		return FormatBinary, nil
+	default:
+		return "", fmt.Errorf("unknown dump format %q, expected jsonl or binary", s)
+	}
+}
+
+// Compression 导出文件的压缩方式
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression 解析命令行中的压缩方式，为空时按文件扩展名推断
+func ParseCompression(s, path string) (Compression, error) {
+	switch Compression(strings.ToLower(s)) {
+	case "":
+		return CompressionFromPath(path), nil
+	case CompressionNone:
+		return CompressionNone, nil
+	case CompressionGzip, "gz":
+		return CompressionGzip, nil
+	case CompressionZstd, "zst":
+		return CompressionZstd, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q, expected none, gzip or zstd", s)
+	}
+}
+
+// CompressionFromPath 按扩展名推断压缩方式：.gz 为 gzip，.zst/.zstd 为 zstd
+func CompressionFromPath(path string) Compression {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return CompressionGzip
+	case ".zst", ".zstd":
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// Header 导出文件的头部信息
+type Header struct {
+	Version    int // 文件格式版本，写入时忽略
+	ClusterID  uint64
+	APIVersion string
+	Keyspace   string
+	// 导出的范围 [Start, End)，End 为空表示不限制
+	Start     []byte
+	End       []byte
+	CreatedAt time.Time
+}
+
+// headerJSON 头部的 JSON 表示，key 的编码规则与 Record 相同
+type headerJSON struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	ClusterID     uint64    `json:"cluster_id,omitempty"`
+	APIVersion    string    `json:"api_version,omitempty"`
+	Keyspace      string    `json:"keyspace,omitempty"`
+	Start         string    `json:"start"`
+	StartEncoding string    `json:"start_encoding,omitempty"`
+	End           string    `json:"end"`
+	EndEncoding   string    `json:"end_encoding,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (h Header) toJSON() headerJSON {
+	j := headerJSON{
+		Format:     FormatName,
+		Version:    Version,
+		ClusterID:  h.ClusterID,
+		APIVersion: h.APIVersion,
+		Keyspace:   h.Keyspace,
+		CreatedAt:  h.CreatedAt,
+	}
+	j.Start, j.StartEncoding = encodeString(h.Start)
+	j.End, j.EndEncoding = encodeString(h.End)
+	return j
+}
+
+func (j headerJSON) header() (Header, error) {
+	if j.Format != FormatName {
+		return Header{}, fmt.Errorf("not a %s file", FormatName)
+	}
+	if j.Version > Version {
+		return Header{}, fmt.Errorf("unsupported dump version %d, this tool supports up to %d", j.Version, Version)
+	}
+
+	h := Header{
+		Version:    j.Version,
+		ClusterID:  j.ClusterID,
+		APIVersion: j.APIVersion,
+		Keyspace:   j.Keyspace,
+		CreatedAt:  j.CreatedAt,
+	}
+	var err error
+	if h.Start, err = decodeString(j.Start, j.StartEncoding); err != nil {
+		return Header{}, fmt.Errorf("invalid start key in header: %v", err)
+	}
+	if h.End, err = decodeString(j.End, j.EndEncoding); err != nil {
+		return Header{}, fmt.Errorf("invalid end key in header: %v", err)
+	}
+	return h, nil
+}
@@ -0,0 +1,281 @@
+package dump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type pair struct {
+	key, value []byte
+}
+
+// testPairs 覆盖普通文本、空值、二进制key和value、包含换行和引号的数据
+var testPairs = []pair{
+	{[]byte("user/1"), []byte(`{"name":"alice"}`)},
+	{[]byte("empty"), []byte{}},
+	{[]byte{0x00, 0xff, 0xfe, 0x01}, []byte("binary key")},
+	{[]byte("binary value"), []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}},
+	{[]byte("multi\nline"), []byte("a \"quoted\"\nvalue\r\n")},
+	{[]byte("中文"), []byte("值")},
+}
+
+var testHeader = Header{
+	ClusterID:  42,
+	APIVersion: "V2",
+	Keyspace:   "users",
+	Start:      []byte{0x00, 0xff},
+	End:        []byte("user0"),
+	CreatedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func writeDump(t *testing.T, format Format, compression Compression, pairs []pair) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, compression, testHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range pairs {
+		if err := w.Write(p.key, p.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readAll 读取所有记录，返回读到的记录和结束时的错误（正常结束为 nil）
+func readAll(data []byte) (*Reader, []pair, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+	var pairs []pair
+	for {
+		key, value, err := r.Next()
+		if err == io.EOF {
+			return r, pairs, nil
+		}
+		if err != nil {
+			return r, pairs, err
+		}
+		pairs = append(pairs, pair{key, value})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSONL, FormatBinary} {
+		for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+			t.Run(fmt.Sprintf("%s/%s", format, compression), func(t *testing.T) {
+				data := writeDump(t, format, compression, testPairs)
+				r, pairs, err := readAll(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if r.Format() != format || !r.HasHeader() {
+					t.Fatalf("Format() = %s, HasHeader() = %v", r.Format(), r.HasHeader())
+				}
+				h := r.Header()
+				if h.Version != Version || h.ClusterID != testHeader.ClusterID || h.APIVersion != testHeader.APIVersion ||
+					h.Keyspace != testHeader.Keyspace || !bytes.Equal(h.Start, testHeader.Start) ||
+					!bytes.Equal(h.End, testHeader.End) || !h.CreatedAt.Equal(testHeader.CreatedAt) {
+					t.Fatalf("Header() = %+v, want %+v", h, testHeader)
+				}
+
+				if len(pairs) != len(testPairs) || r.Count() != int64(len(testPairs)) {
+					t.Fatalf("read %d records (Count %d), want %d", len(pairs), r.Count(), len(testPairs))
+				}
+				for i, p := range pairs {
+					if !bytes.Equal(p.key, testPairs[i].key) || !bytes.Equal(p.value, testPairs[i].value) {
+						t.Errorf("record %d = %q: %q, want %q: %q", i, p.key, p.value, testPairs[i].key, testPairs[i].value)
+					}
+					// 空值需要和只有key的记录区分开
+					if p.value == nil {
+						t.Errorf("record %d value is nil, want an empty value", i)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEmptyDump(t *testing.T) {
+	for _, format := range []Format{FormatJSONL, FormatBinary} {
+		_, pairs, err := readAll(writeDump(t, format, CompressionNone, nil))
+		if err != nil || len(pairs) != 0 {
+			t.Errorf("%s: read %d records, err %v", format, len(pairs), err)
+		}
+	}
+}
+
+func TestTruncatedDump(t *testing.T) {
+	for _, format := range []Format{FormatJSONL, FormatBinary} {
+		for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+			t.Run(fmt.Sprintf("%s/%s", format, compression), func(t *testing.T) {
+				full := writeDump(t, format, CompressionNone, testPairs)
+				// 去掉尾部以及截断在记录中间
+				footerStart := bytes.LastIndex(full, []byte(`{"end"`))
+				if format == FormatBinary {
+					footerStart = bytes.LastIndexByte(full, tagEnd)
+				}
+				cuts := map[string][]byte{
+					"missing footer": full[:footerStart],
+					"mid record":     full[:footerStart-3],
+				}
+				for name, data := range cuts {
+					if _, _, err := readAll(compress(t, compression, data)); err == nil {
+						t.Errorf("%s: truncated dump was accepted", name)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTruncatedDumpIsErrTruncated(t *testing.T) {
+	full := writeDump(t, FormatBinary, CompressionNone, testPairs)
+	_, _, err := readAll(full[:bytes.LastIndexByte(full, tagEnd)])
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("binary dump without footer: err = %v, want ErrTruncated", err)
+	}
+
+	full = writeDump(t, FormatJSONL, CompressionNone, testPairs)
+	_, _, err = readAll(full[:bytes.LastIndex(full, []byte(`{"end"`))])
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("JSONL dump without footer: err = %v, want ErrTruncated", err)
+	}
+
+	// 压缩流被截断时同样不能当作完整的文件
+	gz := writeDump(t, FormatJSONL, CompressionGzip, testPairs)
+	if _, _, err := readAll(gz[:len(gz)-4]); err == nil {
+		t.Fatal("truncated gzip stream was accepted")
+	}
+}
+
+// compress 用与 Writer 相同的压缩算法压缩任意数据
+func compress(t *testing.T, compression Compression, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = enc
+	default:
+		return data
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFooterCountMismatch(t *testing.T) {
+	jsonl := writeDump(t, FormatJSONL, CompressionNone, testPairs)
+	jsonl = bytes.Replace(jsonl, []byte(fmt.Sprintf(`"count":%d`, len(testPairs))), []byte(`"count":99`), 1)
+	if _, _, err := readAll(jsonl); err == nil || !strings.Contains(err.Error(), "footer says 99") {
+		t.Errorf("JSONL: err = %v, want a footer count mismatch", err)
+	}
+
+	binary := writeDump(t, FormatBinary, CompressionNone, testPairs)
+	binary[len(binary)-1] = 99
+	if _, _, err := readAll(binary); err == nil || !strings.Contains(err.Error(), "footer says 99") {
+		t.Errorf("binary: err = %v, want a footer count mismatch", err)
+	}
+}
+
+func TestDataAfterFooter(t *testing.T) {
+	data := append(writeDump(t, FormatJSONL, CompressionNone, testPairs), []byte(`{"key":"late","value":"x"}`+"\n")...)
+	if _, _, err := readAll(data); err == nil {
+		t.Fatal("data after the footer was accepted")
+	}
+}
+
+func TestHeaderlessJSONL(t *testing.T) {
+	// scan --output jsonl 的输出：没有头部和尾部，可能有空行
+	data := []byte(`{"key":"a","value":"1"}
+{"key":"AP8=","key_encoding":"base64","value":"2"}
+
+{"key":"c","value":""}
+`)
+	r, pairs, err := readAll(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.HasHeader() {
+		t.Fatal("HasHeader() = true for a headerless file")
+	}
+	want := []pair{{[]byte("a"), []byte("1")}, {[]byte{0x00, 0xff}, []byte("2")}, {[]byte("c"), []byte{}}}
+	if len(pairs) != len(want) {
+		t.Fatalf("read %d records, want %d", len(pairs), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(pairs[i].key, want[i].key) || !bytes.Equal(pairs[i].value, want[i].value) {
+			t.Errorf("record %d = %q: %q, want %q: %q", i, pairs[i].key, pairs[i].value, want[i].key, want[i].value)
+		}
+	}
+
+	// --keys-only 的输出只有key，value 为 nil
+	_, pairs, err = readAll([]byte(`{"key":"a"}` + "\n"))
+	if err != nil || len(pairs) != 1 || pairs[0].value != nil {
+		t.Fatalf("keys-only listing = %q, %v", pairs, err)
+	}
+}
+
+func TestInvalidDump(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"not json":       "hello\n",
+		"other format":   `{"format":"something-else","version":1}` + "\n",
+		"newer version":  `{"format":"tikvtool-dump","version":99}` + "\n",
+		"bad encoding":   `{"key":"a","key_encoding":"rot13"}` + "\n",
+		"invalid base64": `{"key":"!!","key_encoding":"base64"}` + "\n",
+	}
+	for name, data := range tests {
+		if _, _, err := readAll([]byte(data)); err == nil {
+			t.Errorf("%s: invalid dump was accepted", name)
+		}
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		flag, path string
+		want       Compression
+	}{
+		{"", "out.jsonl", CompressionNone},
+		{"", "out.jsonl.gz", CompressionGzip},
+		{"", "out.bin.zst", CompressionZstd},
+		{"gzip", "out.jsonl", CompressionGzip},
+		{"none", "out.gz", CompressionNone},
+	}
+	for _, tt := range tests {
+		got, err := ParseCompression(tt.flag, tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCompression(%q, %q) = %s, %v, want %s", tt.flag, tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := ParseCompression("lz4", ""); err == nil {
+		t.Error("ParseCompression(lz4) succeeded")
+	}
+}
@@ -0,0 +1,311 @@
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrTruncated 文件在尾部之前结束，通常是导出被中断
+var ErrTruncated = errors.New("dump file is truncated")
+
+// maxRecordSize 单个key或value的长度上限，防止损坏的文件导致分配过多内存
+const maxRecordSize = 1 << 30
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Reader 读取导出文件，自动识别压缩方式和格式。
+// 也可以读取没有头部的 JSON Lines 文件（例如 scan 的输出），此时 HasHeader 返回 false
+type Reader struct {
+	in           *bufio.Reader
+	decompressor io.Closer
+	format       Format
+	header       Header
+	hasHeader    bool
+	pending      *Record // 无头部文件中已读取的第一条记录
+	count        int64
+	done         bool
+}
+
+// NewReader 读取文件头部
+func NewReader(r io.Reader) (*Reader, error) {
+	dr := &Reader{}
+
+	in := bufio.NewReader(r)
+	magic, _ := in.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		dr.decompressor = gz
+		in = bufio.NewReader(gz)
+	case bytes.HasPrefix(magic, zstdMagic):
+		dec, err := zstd.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		dr.decompressor = dec.IOReadCloser()
+		in = bufio.NewReader(dec)
+	}
+	dr.in = bufio.NewReaderSize(in, 256*1024)
+
+	if magic, _ := dr.in.Peek(len(binaryMagic)); string(magic) == binaryMagic {
+		dr.format = FormatBinary
+		if err := dr.readBinaryHeader(); err != nil {
+			dr.Close()
+			return nil, err
+		}
+	} else {
+		dr.format = FormatJSONL
+		if err := dr.readJSONHeader(); err != nil {
+			dr.Close()
+			return nil, err
+		}
+	}
+
+	return dr, nil
+}
+
+// Format 文件的格式
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+// Header 文件的头部信息
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// HasHeader 文件是否包含头部
+func (r *Reader) HasHeader() bool {
+	return r.hasHeader
+}
+
+// Count 已读取的记录数
+func (r *Reader) Count() int64 {
+	return r.count
+}
+
+// Next 读取下一个键值对，读到尾部后返回 io.EOF。
+// 有头部的文件在尾部之前结束时返回 ErrTruncated
+func (r *Reader) Next() (key, value []byte, err error) {
+	if r.done {
+		return nil, nil, io.EOF
+	}
+	if r.format == FormatBinary {
+		key, value, err = r.nextBinary()
+	} else {
+		key, value, err = r.nextJSON()
+	}
+	if err == io.EOF {
+		r.done = true
+		if r.hasHeader {
+			err = r.checkTrailing()
+		}
+	} else if err == nil {
+		r.count++
+	}
+	return key, value, err
+}
+
+// checkTrailing 尾部之后不应再有数据，同时让解压缩流校验文件末尾的校验和
+func (r *Reader) checkTrailing() error {
+	_, err := r.in.ReadByte()
+	switch err {
+	case io.EOF:
+		return io.EOF
+	case nil:
+		return fmt.Errorf("unexpected data after the end of the dump")
+	case io.ErrUnexpectedEOF:
+		return ErrTruncated
+	default:
+		return err
+	}
+}
+
+// Close 释放解压缩使用的资源，不会关闭底层的 io.Reader
+func (r *Reader) Close() error {
+	if r.decompressor != nil {
+		return r.decompressor.Close()
+	}
+	return nil
+}
+
+// jsonLine JSON Lines 格式中的一行，可能是记录或尾部
+type jsonLine struct {
+	Record
+	End   bool  `json:"end"`
+	Count int64 `json:"count"`
+}
+
+func (r *Reader) readJSONHeader() error {
+	line, err := r.readLine()
+	if err == io.EOF {
+		return fmt.Errorf("empty dump file")
+	}
+	if err != nil {
+		return err
+	}
+
+	var probe struct {
+		Format string `json:"format"`
+	}
+	if err := json.Unmarshal(line, &probe); err != nil {
+		return fmt.Errorf("invalid JSON in line 1: %v", err)
+	}
+	if probe.Format == "" {
+		// 没有头部，第一行就是记录
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("invalid record in line 1: %v", err)
+		}
+		r.pending = &record
+		return nil
+	}
+
+	var h headerJSON
+	if err := json.Unmarshal(line, &h); err != nil {
+		return fmt.Errorf("invalid dump header: %v", err)
+	}
+	if r.header, err = h.header(); err != nil {
+		return err
+	}
+	r.hasHeader = true
+	return nil
+}
+
+func (r *Reader) nextJSON() ([]byte, []byte, error) {
+	if r.pending != nil {
+		record := *r.pending
+		r.pending = nil
+		return record.Bytes()
+	}
+
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			if r.hasHeader {
+				return nil, nil, ErrTruncated
+			}
+			return nil, nil, io.EOF
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var l jsonLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			return nil, nil, fmt.Errorf("invalid record after %d records: %v", r.count, err)
+		}
+		if l.End {
+			if l.Count != r.count {
+				return nil, nil, fmt.Errorf("dump file has %d records but its footer says %d", r.count, l.Count)
+			}
+			return nil, nil, io.EOF
+		}
+		return l.Record.Bytes()
+	}
+}
+
+// readLine 读取一行，不包含换行符
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.in.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+func (r *Reader) readBinaryHeader() error {
+	if _, err := r.in.Discard(len(binaryMagic)); err != nil {
+		return err
+	}
+	version, err := r.in.ReadByte()
+	if err != nil {
+		return ErrTruncated
+	}
+	if version > Version {
+		return fmt.Errorf("unsupported dump version %d, this tool supports up to %d", version, Version)
+	}
+
+	data, err := r.readBytes()
+	if err != nil {
+		return err
+	}
+	var h headerJSON
+	if err := json.Unmarshal(data, &h); err != nil {
+		return fmt.Errorf("invalid dump header: %v", err)
+	}
+	if r.header, err = h.header(); err != nil {
+		return err
+	}
+	r.hasHeader = true
+	return nil
+}
+
+func (r *Reader) nextBinary() ([]byte, []byte, error) {
+	tag, err := r.in.ReadByte()
+	if err == io.EOF {
+		return nil, nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch tag {
+	case tagRecord:
+		key, err := r.readBytes()
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := r.readBytes()
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, value, nil
+	case tagEnd:
+		count, err := binary.ReadUvarint(r.in)
+		if err != nil {
+			return nil, nil, ErrTruncated
+		}
+		if int64(count) != r.count {
+			return nil, nil, fmt.Errorf("dump file has %d records but its footer says %d", r.count, count)
+		}
+		return nil, nil, io.EOF
+	default:
+		return nil, nil, fmt.Errorf("invalid record tag 0x%02x after %d records", tag, r.count)
+	}
+}
+
+func (r *Reader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(r.in)
+	if err != nil {
+		return nil, ErrTruncated
+	}
+	if n > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes is too large, the file may be corrupted", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r.in, data); err != nil {
+		return nil, ErrTruncated
+	}
+	return data, nil
+}
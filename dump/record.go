@@ -0,0 +1,61 @@
+package dump
+
+import (
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+)
+
+// EncodingBase64 JSON 中使用 base64 表示非 UTF-8 数据时的 encoding 字段值
+const EncodingBase64 = "base64"
+
+// Record JSON 中的一个键值对，非 UTF-8 的数据使用 base64 编码，
+// 此时对应的 encoding 字段为 base64。Value 为空表示只有key
+type Record struct {
+	Key           string  `json:"key"`
+	KeyEncoding   string  `json:"key_encoding,omitempty"`
+	Value         *string `json:"value,omitempty"`
+	ValueEncoding string  `json:"value_encoding,omitempty"`
+}
+
+// NewRecord 构造键值对，value 为 nil 时只包含key
+func NewRecord(key, value []byte) Record {
+	var r Record
+	r.Key, r.KeyEncoding = encodeString(key)
+	if value != nil {
+		v, enc := encodeString(value)
+		r.Value, r.ValueEncoding = &v, enc
+	}
+	return r
+}
+
+// Bytes 还原原始的key和value，只有key时 value 为 nil
+func (r Record) Bytes() (key, value []byte, err error) {
+	if key, err = decodeString(r.Key, r.KeyEncoding); err != nil {
+		return nil, nil, fmt.Errorf("invalid key: %v", err)
+	}
+	if r.Value != nil {
+		if value, err = decodeString(*r.Value, r.ValueEncoding); err != nil {
+			return nil, nil, fmt.Errorf("invalid value: %v", err)
+		}
+	}
+	return key, value, nil
+}
+
+func encodeString(data []byte) (string, string) {
+	if utf8.Valid(data) {
+		return string(data), ""
+	}
+	return base64.StdEncoding.EncodeToString(data), EncodingBase64
+}
+
+func decodeString(s, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(s), nil
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
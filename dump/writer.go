@@ -0,0 +1,134 @@
+package dump
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Writer 以流式方式写入导出文件
+type Writer struct {
+	format     Format
+	out        *bufio.Writer
+	compressor io.WriteCloser // 未压缩时为空
+	encoder    *json.Encoder
+	count      int64
+	buf        [binary.MaxVarintLen64]byte
+}
+
+// NewWriter 创建导出文件并写入头部，Close 时写入尾部，不会关闭 w
+func NewWriter(w io.Writer, format Format, compression Compression, header Header) (*Writer, error) {
+	dw := &Writer{format: format}
+
+	switch compression {
+	case CompressionNone, "":
+	case CompressionGzip:
+		dw.compressor = gzip.NewWriter(w)
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		dw.compressor = enc
+	default:
+		return nil, fmt.Errorf("unknown compression %q", compression)
+	}
+	if dw.compressor != nil {
+		w = dw.compressor
+	}
+	dw.out = bufio.NewWriterSize(w, 256*1024)
+
+	headerData, err := json.Marshal(header.toJSON())
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSONL:
+		dw.encoder = json.NewEncoder(dw.out)
+		dw.encoder.SetEscapeHTML(false)
+		_, err = dw.out.Write(append(headerData, '\n'))
+	case FormatBinary:
+		dw.out.WriteString(binaryMagic)
+		dw.out.WriteByte(Version)
+		err = dw.writeBytes(headerData)
+	default:
+		return nil, fmt.Errorf("unknown dump format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return dw, nil
+}
+
+// Write 写入一个键值对
+func (w *Writer) Write(key, value []byte) error {
+	var err error
+	switch w.format {
+	case FormatJSONL:
+		if value == nil {
+			value = []byte{}
+		}
+		err = w.encoder.Encode(NewRecord(key, value))
+	default:
+		w.out.WriteByte(tagRecord)
+		if err = w.writeBytes(key); err == nil {
+			err = w.writeBytes(value)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Count 已写入的记录数
+func (w *Writer) Count() int64 {
+	return w.count
+}
+
+// Close 写入尾部并刷新压缩流
+func (w *Writer) Close() error {
+	var err error
+	switch w.format {
+	case FormatJSONL:
+		err = w.encoder.Encode(footer{End: true, Count: w.count})
+	default:
+		w.out.WriteByte(tagEnd)
+		n := binary.PutUvarint(w.buf[:], uint64(w.count))
+		_, err = w.out.Write(w.buf[:n])
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := w.out.Flush(); err != nil {
+		return err
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+func (w *Writer) writeBytes(data []byte) error {
+	n := binary.PutUvarint(w.buf[:], uint64(len(data)))
+	if _, err := w.out.Write(w.buf[:n]); err != nil {
+		return err
+	}
+	_, err := w.out.Write(data)
+	return err
+}
+
+// footer JSON Lines 格式的尾部
+type footer struct {
+	End   bool  `json:"end"`
+	Count int64 `json:"count"`
+}
@@ -1,10 +1,12 @@
 package ui
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/baixiaoshi/tikvtool/dao"
@@ -14,9 +16,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// KeyValue 保存原始字节，显示时再按 displayEncoding 渲染，避免二进制数据破坏终端
 type KeyValue struct {
-	Key   string
-	Value string
+	Key   []byte
+	Value []byte // 搜索列表只扫描key，value 在打开详情时再读取
 }
 
 type viewMode int
@@ -27,6 +30,8 @@ const (
 	modeDetail
 	modeEdit
 	modeAdd
+	modeKeyspace
+	modeConnect
 )
 
 type model struct {
@@ -35,15 +40,33 @@ type model struct {
 	results      []KeyValue
 	selectedItem int
 	searching    bool
-	kvClient     *dao.RawKv
+	kvClient     dao.KV
 	ctx          context.Context
 
 	// 新增字段
 	mode         viewMode
 	detailValue  string
-	detailKey    string
+	detailKey    []byte
 	resultOffset int // 结果列表滚动偏移
 
+	// 显示编码
+	displayEncoding utils.Encoding // 用户选择的显示编码
+	detailRaw       []byte         // 详情的原始 value，切换编码时重新渲染
+	detailEncoding  utils.Encoding // 详情 value 实际使用的编码，保存时按此编码还原
+
+	// 十六进制视图
+	detailHexMode bool   // 详情是否显示为十六进制视图
+	hexCursor     int    // 十六进制视图光标所在的字节偏移
+	hexOffset     int    // 十六进制视图第一行的字节偏移
+	hexJumping    bool   // 是否正在输入跳转的偏移
+	hexJumpInput  string // 跳转偏移的输入内容
+
+	// 分页加载
+	hasMore     bool   // 是否还有未加载的结果
+	nextKey     []byte // 下一页的起始key
+	loadingMore bool   // 是否正在加载下一页
+	searchGen   int    // 搜索的代数，重新搜索或重置结果列表时递增，丢弃旧代数的搜索和分页结果
+
 	// 编辑相关字段
 	editValue         string
 	editCursor        int
@@ -58,6 +81,8 @@ type model struct {
 	detailCursorLine  int          // 详情模式光标行号
 	detailCursorCol   int          // 详情模式光标列号
 	detailLines       []string     // 详情模式的文本行
+	detailLoading     bool         // 是否正在读取详情的 value
+	detailLoadErr     string       // value 读取失败或key不存在的原因，非空时不允许编辑和保存
 	valueFormat       utils.Format // 当前值的格式
 
 	// 添加模式相关字段
@@ -73,20 +98,112 @@ type model struct {
 	filteredCommands []Command // 过滤后的命令列表
 	selectedCommand  int       // 选中的命令索引
 	commandOffset    int       // 命令列表滚动偏移
+
+	// 连接信息
+	clusterName string // 当前集群（profile）名称
+	apiVersion  string // 当前使用的 API 版本
+
+	// keyspace 切换
+	keyspace         string           // 当前 keyspace，为空表示默认 keyspace
+	keyspaceSwitcher KeyspaceSwitcher // 切换 keyspace 的回调，为空时不支持切换
+	keyspaces        []dao.Keyspace   // PD 中的 keyspace 列表
+	selectedKeyspace int              // 选中的 keyspace 索引
+	keyspaceOffset   int              // keyspace 列表滚动偏移
+	loadingKeyspaces bool             // 是否正在加载 keyspace 列表
+
+	// 集群切换
+	clusterNames     []string               // 可切换的集群（profile）列表
+	clusterConnector ClusterConnector       // 连接集群的回调，为空时不支持切换
+	clusterStates    map[string]searchState // 每个集群的搜索状态
+	selectedCluster  int                    // 选中的集群索引
+	clusterOffset    int                    // 集群列表滚动偏移
+	connecting       bool                   // 是否正在连接集群
+
+	// 受保护的key前缀
+	protected []ProtectedPrefix // 当前集群的保护规则
+	confirm   *pendingWrite     // 等待确认的修改，不为空时显示确认框
+}
+
+// searchState 切换集群时保存的搜索状态
+type searchState struct {
+	input        string
+	cursor       int
+	results      []KeyValue
+	selectedItem int
+	resultOffset int
+	hasMore      bool
+	nextKey      []byte
+}
+
+// KeyspaceSwitcher 连接到指定 keyspace 并返回新的数据访问对象
+type KeyspaceSwitcher func(ctx context.Context, keyspace string) (dao.KV, error)
+
+// Cluster 一个已连接的集群
+type Cluster struct {
+	Name           string
+	ApiVersion     string
+	Keyspace       string
+	KV             dao.KV
+	SwitchKeyspace KeyspaceSwitcher  // 为空表示不支持切换 keyspace
+	Protected      []ProtectedPrefix // 受保护的key前缀
+}
+
+// ClusterConnector 按名称连接集群
+type ClusterConnector func(ctx context.Context, name string) (*Cluster, error)
+
+// ModelOpt 初始化界面模型的可选配置
+type ModelOpt func(*model)
+
+// WithCluster 设置当前集群的名称、API 版本和 keyspace
+func WithCluster(cluster *Cluster) ModelOpt {
+	return func(m *model) {
+		m.clusterName = cluster.Name
+		m.apiVersion = cluster.ApiVersion
+		m.keyspace = cluster.Keyspace
+		m.keyspaceSwitcher = cluster.SwitchKeyspace
+		m.protected = cluster.Protected
+	}
+}
+
+// WithClusters 设置可切换的集群列表，connector 不为空时启用 /connect 命令
+func WithClusters(names []string, connector ClusterConnector) ModelOpt {
+	return func(m *model) {
+		m.clusterNames = names
+		m.clusterConnector = connector
+	}
 }
 
 type searchResultMsg struct {
-	results []KeyValue
-	err     error
+	gen        int    // 发起请求时的搜索代数
+	cluster    string // 发起搜索时的集群
+	keyspace   string // 发起搜索时的 keyspace
+	query      string // 结果对应的搜索输入
+	results    []KeyValue
+	hasMore    bool   // 是否还有下一页
+	next       []byte // 下一页的起始key
+	appendPage bool   // 是否为追加加载的下一页
+	err        error
+}
+
+type detailLoadedMsg struct {
+	key   []byte
+	value []byte
+	found bool
+	err   error
 }
 
 type deleteSuccessMsg struct {
-	key string
+	key []byte
+}
+
+type deleteErrorMsg struct {
+	key []byte
+	err error
 }
 
 type saveSuccessMsg struct {
-	key          string
-	value        string
+	key          []byte
+	value        []byte
 	exitToDetail bool // 是否退出到详细视图
 }
 
@@ -101,11 +218,29 @@ type Command struct {
 }
 
 type saveErrorMsg struct {
-	key string
+	key []byte
 	err error
 }
 
-func InitialModel(ctx context.Context, kvClient *dao.RawKv) model {
+type keyspaceListMsg struct {
+	keyspaces []dao.Keyspace
+	err       error
+}
+
+type keyspaceSwitchMsg struct {
+	cluster  string
+	keyspace string
+	kvClient dao.KV
+	err      error
+}
+
+type clusterConnectMsg struct {
+	name    string
+	cluster *Cluster
+	err     error
+}
+
+func InitialModel(ctx context.Context, kvClient dao.KV, opts ...ModelOpt) model {
 	// 初始化日志文件
 	logFile, err := os.OpenFile("/tmp/test.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err == nil {
@@ -113,7 +248,7 @@ func InitialModel(ctx context.Context, kvClient *dao.RawKv) model {
 		log.Println("InitialModel: detailCommandMode set to true")
 	}
 
-	return model{
+	m := model{
 		input:             "",
 		cursor:            0,
 		results:           []KeyValue{},
@@ -125,17 +260,17 @@ func InitialModel(ctx context.Context, kvClient *dao.RawKv) model {
 		resultOffset:      0,
 		detailCommandMode: true, // 默认详情模式为命令模式
 		isInCommand:       true, // Main模式默认是命令模式
-		commandList: []Command{
-			{Name: "/search", Description: "Search keys by prefix"},
-			{Name: "/add", Description: "Add new key-value pair"},
-		},
-		filteredCommands: []Command{
-			{Name: "/search", Description: "Search keys by prefix"},
-			{Name: "/add", Description: "Add new key-value pair"},
-		},
-		selectedCommand: 0,
-		commandOffset:   0,
+		selectedCommand:   0,
+		commandOffset:     0,
+		clusterStates:     map[string]searchState{},
+	}
+
+	for _, opt := range opts {
+		opt(&m)
 	}
+	m.refreshCommands()
+
+	return m
 }
 
 func (m model) Init() tea.Cmd {
@@ -145,6 +280,9 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
 		switch m.mode {
 		case modeMain:
 			return m.updateMain(msg)
@@ -156,24 +294,79 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateEdit(msg)
 		case modeAdd:
 			return m.updateAdd(msg)
+		case modeKeyspace:
+			return m.updateKeyspace(msg)
+		case modeConnect:
+			return m.updateConnect(msg)
 		}
 
 	case searchResultMsg:
+		if msg.gen != m.searchGen || msg.cluster != m.clusterName || msg.keyspace != m.keyspace || msg.query != m.input {
+			// 之后已经重新搜索、切换了集群或 keyspace，丢弃过期的结果
+			return m, nil
+		}
 		m.searching = false
-		if msg.err == nil {
+		if msg.appendPage {
+			m.loadingMore = false
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Search failed: %v", msg.err)
+			return m, nil
+		}
+		if msg.appendPage {
+			m.results = append(m.results, msg.results...)
+		} else {
 			m.results = msg.results
 			m.selectedItem = 0
 			m.resultOffset = 0
 		}
+		m.hasMore = msg.hasMore
+		m.nextKey = msg.next
 
 	case deleteSuccessMsg:
 		// 删除成功，返回搜索视图并刷新结果
 		m.mode = modeSearch
-		return m, m.searchCmd()
+		m.statusMessage = fmt.Sprintf("Deleted key '%s'", m.displayKey(msg.key))
+		cmd := m.searchCmd()
+		return m, cmd
+
+	case detailLoadedMsg:
+		if !bytes.Equal(msg.key, m.detailKey) {
+			// 已经打开了其他key，忽略
+			return m, nil
+		}
+		m.detailLoading = false
+		m.detailRaw = nil
+		m.detailLoadErr = ""
+		switch {
+		case msg.err != nil:
+			m.detailValue = ""
+			m.detailLoadErr = fmt.Sprintf("failed to load value: %v", msg.err)
+			m.detailLines = []string{"<" + m.detailLoadErr + ">"}
+			m.statusMessage = m.detailLoadErr
+		case !msg.found:
+			m.detailValue = ""
+			m.detailLoadErr = "key not found"
+			m.detailLines = []string{"<key not found>"}
+		default:
+			m.detailRaw = msg.value
+			m.detailValue = m.formatValue(msg.value)
+			m.detailLines = strings.Split(m.detailValue, "\n")
+		}
+		m.detailCursorLine = 0
+		m.detailCursorCol = 0
+		return m, nil
+
+	case deleteErrorMsg:
+		m.statusMessage = fmt.Sprintf("Delete failed: %v", msg.err)
+		return m, nil
 
 	case saveSuccessMsg:
 		// 保存成功，更新详细视图的内容
-		m.detailValue = m.formatJSON(msg.value)
+		m.detailRaw = msg.value
+		m.detailValue = m.formatValue(msg.value)
+		m.detailLines = strings.Split(m.detailValue, "\n")
+		m.moveHexCursor(0)
 		m.statusMessage = "Saved successfully!"
 		if msg.exitToDetail {
 			// 如果是 :x 或 :wq 命令，退出到详细视图
@@ -196,8 +389,100 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.addStep = 0
 		m.addCursor = 0
 		m.statusMessage = fmt.Sprintf("Added key '%s' successfully!", msg.key)
-		return m, m.searchCmd()
+		cmd := m.searchCmd()
+		return m, cmd
 
+	case keyspaceListMsg:
+		m.loadingKeyspaces = false
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Load keyspaces failed: %v", msg.err)
+			return m, nil
+		}
+		m.keyspaces = msg.keyspaces
+		m.selectedKeyspace = 0
+		m.keyspaceOffset = 0
+		// 默认选中当前 keyspace
+		for i, ks := range m.keyspaces {
+			if ks.Name == m.keyspace {
+				m.selectedKeyspace = i
+				if i >= 10 {
+					m.keyspaceOffset = i - 9
+				}
+				break
+			}
+		}
+		return m, nil
+
+	case keyspaceSwitchMsg:
+		if msg.cluster != m.clusterName {
+			// 切换过程中已经连接到其他集群，忽略
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Switch keyspace failed: %v", msg.err)
+			return m, nil
+		}
+		// 切换成功，清空旧 keyspace 的搜索结果并返回 Main 模式
+		m.kvClient = msg.kvClient
+		m.keyspace = msg.keyspace
+		m.searchGen++
+		m.results = []KeyValue{}
+		m.selectedItem = 0
+		m.resultOffset = 0
+		m.hasMore = false
+		m.nextKey = nil
+		m.loadingMore = false
+		m.input = ""
+		m.cursor = 0
+		m.mode = modeMain
+		m.isInCommand = true
+		m.commandPrefix = ""
+		m.filterCommands()
+		m.statusMessage = fmt.Sprintf("Switched to keyspace '%s'", msg.keyspace)
+		return m, nil
+
+	case clusterConnectMsg:
+		m.connecting = false
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Connect to '%s' failed: %v", msg.name, msg.err)
+			return m, nil
+		}
+		// 保存当前集群的搜索结果，恢复目标集群之前的搜索结果
+		m.clusterStates[m.clusterName] = searchState{
+			input:        m.input,
+			cursor:       m.cursor,
+			results:      m.results,
+			selectedItem: m.selectedItem,
+			resultOffset: m.resultOffset,
+			hasMore:      m.hasMore,
+			nextKey:      m.nextKey,
+		}
+		state := m.clusterStates[msg.cluster.Name]
+		m.input = state.input
+		m.cursor = state.cursor
+		m.results = state.results
+		if m.results == nil {
+			m.results = []KeyValue{}
+		}
+		m.selectedItem = state.selectedItem
+		m.resultOffset = state.resultOffset
+		m.hasMore = state.hasMore
+		m.nextKey = state.nextKey
+		m.loadingMore = false
+		m.searchGen++
+
+		m.kvClient = msg.cluster.KV
+		m.clusterName = msg.cluster.Name
+		m.apiVersion = msg.cluster.ApiVersion
+		m.keyspace = msg.cluster.Keyspace
+		m.keyspaceSwitcher = msg.cluster.SwitchKeyspace
+		m.protected = msg.cluster.Protected
+		m.mode = modeMain
+		m.isInCommand = true
+		m.commandPrefix = ""
+		m.refreshCommands()
+		m.statusMessage = fmt.Sprintf("Connected to cluster '%s'", msg.cluster.Name)
+		return m, nil
 	}
 
 	return m, nil
@@ -274,20 +559,35 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.cursor = 0
 		return m, nil
 
+	case tea.KeyCtrlT:
+		// 切换key的显示编码
+		m.toggleEncoding()
+		return m, nil
+
 	case tea.KeyEnter:
 		if len(m.results) > 0 && m.selectedItem < len(m.results) {
 			// 进入详细视图，默认为命令模式
 			log.Printf("Enter pressed: setting detailCommandMode to true, current value: %v", m.detailCommandMode)
 			m.mode = modeDetail
 			m.detailKey = m.results[m.selectedItem].Key
-			m.detailValue = m.formatValue(m.results[m.selectedItem].Value)
-			m.detailCommandMode = true                         // 默认进入命令模式
-			m.detailLines = strings.Split(m.detailValue, "\n") // 分割文本行
-			m.detailCursorLine = 0                             // 光标在第一行
-			m.detailCursorCol = 0                              // 光标在第一列
+			m.detailLoading = true
+			m.detailLoadErr = ""
+			m.detailRaw = nil
+			m.detailEncoding = utils.EncodingUTF8
+			m.detailHexMode = false
+			m.hexJumping = false
+			m.hexCursor = 0
+			m.hexOffset = 0
+			m.detailValue = ""
+			m.valueFormat = utils.FormatPlainText
+			m.detailCommandMode = true             // 默认进入命令模式
+			m.detailLines = []string{"Loading..."} // value 读取完成后替换
+			m.detailCursorLine = 0                 // 光标在第一行
+			m.detailCursorCol = 0                  // 光标在第一列
 			m.waitingForSecondD = false
+			m.statusMessage = ""
 			log.Printf("After setting: detailCommandMode = %v, lines = %d", m.detailCommandMode, len(m.detailLines))
-			return m, nil
+			return m, m.loadDetailCmd(m.detailKey)
 		}
 
 	case tea.KeyUp:
@@ -307,6 +607,11 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.resultOffset = m.selectedItem - 9
 			}
 		}
+		// 光标到达已加载结果的底部时加载下一页
+		if m.selectedItem >= len(m.results)-1 && m.hasMore && !m.loadingMore {
+			m.loadingMore = true
+			return m, m.loadMoreCmd()
+		}
 
 	case tea.KeyLeft:
 		if m.cursor > 0 {
@@ -322,7 +627,8 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.cursor > 0 && len(m.input) > 0 {
 			m.input = m.input[:m.cursor-1] + m.input[m.cursor:]
 			m.cursor--
-			return m, m.searchCmd()
+			cmd := m.searchCmd()
+			return m, cmd
 		}
 
 	case tea.KeyRunes:
@@ -332,8 +638,12 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.waitingForSecondD {
 				// 第二个d，执行删除选中的key
 				m.waitingForSecondD = false
+				if m.readOnly() {
+					m.statusMessage = readOnlyMessage
+					return m, nil
+				}
 				if len(m.results) > 0 && m.selectedItem < len(m.results) {
-					return m, m.deleteSelectedKeyCmd()
+					return m.guardWrite("delete", m.results[m.selectedItem].Key, m.deleteSelectedKeyCmd())
 				}
 				return m, nil
 			} else {
@@ -347,7 +657,8 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if len(msg.String()) == 1 {
 				m.input = m.input[:m.cursor] + msg.String() + m.input[m.cursor:]
 				m.cursor++
-				return m, m.searchCmd()
+				cmd := m.searchCmd()
+				return m, cmd
 			}
 		}
 
@@ -360,15 +671,25 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.detailHexMode {
+		return m.updateHexView(msg)
+	}
+
 	switch msg.Type {
 	case tea.KeyEsc:
 		// 返回搜索视图
 		m.mode = modeSearch
+		m.statusMessage = ""
 		return m, nil
 
 	case tea.KeyCtrlC:
 		return m, tea.Quit
 
+	case tea.KeyCtrlT:
+		// 切换key和value的显示编码
+		m.toggleEncoding()
+		return m, nil
+
 	case tea.KeyRunes:
 		if m.detailCommandMode {
 			// 命令模式下的按键处理
@@ -376,6 +697,13 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			switch string(msg.Runes) {
 			case "i", "I":
 				// Vi风格：i进入编辑模式（命令模式）
+				if !m.checkDetailLoaded() {
+					return m, nil
+				}
+				if m.readOnly() {
+					m.statusMessage = readOnlyMessage
+					return m, nil
+				}
 				m.mode = modeEdit
 				m.editValue = m.detailValue
 				m.editLines = strings.Split(m.editValue, "\n")
@@ -388,7 +716,11 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if m.waitingForSecondD {
 					// 第二个d，执行删除当前key
 					m.waitingForSecondD = false
-					return m, m.deleteCurrentKeyCmd()
+					if m.readOnly() {
+						m.statusMessage = readOnlyMessage
+						return m, nil
+					}
+					return m.guardWrite("delete", m.detailKey, m.deleteCurrentKeyCmd())
 				} else {
 					// 第一个d，等待第二个d
 					m.waitingForSecondD = true
@@ -399,6 +731,11 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.detailCommandMode = false
 				m.waitingForSecondD = false
 				return m, nil
+			case "x":
+				// 切换到十六进制视图
+				m.waitingForSecondD = false
+				m.enterHexView()
+				return m, nil
 			case "j":
 				// 向下移动光标
 				log.Printf("COMMAND mode: j key pressed (move cursor down)")
@@ -456,6 +793,13 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			switch string(msg.Runes) {
 			case "i", "I":
 				// Vi风格：i进入编辑模式（命令模式）
+				if !m.checkDetailLoaded() {
+					return m, nil
+				}
+				if m.readOnly() {
+					m.statusMessage = readOnlyMessage
+					return m, nil
+				}
 				m.mode = modeEdit
 				m.editValue = m.detailValue
 				m.editLines = strings.Split(m.editValue, "\n")
@@ -468,6 +812,10 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.detailCommandMode = true
 				m.waitingForSecondD = false
 				return m, nil
+			case "x":
+				// 切换到十六进制视图
+				m.enterHexView()
+				return m, nil
 			}
 		}
 
@@ -476,22 +824,174 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// formatValue 格式化值并返回格式信息
-func (m *model) formatValue(value string) string {
+// hexPageRows 十六进制视图每页显示的行数
+const hexPageRows = 16
+
+// enterHexView 以十六进制视图显示当前 value
+func (m *model) enterHexView() {
+	if m.detailLoading || m.detailRaw == nil {
+		m.statusMessage = "No value to show in hex view"
+		return
+	}
+	m.detailHexMode = true
+	m.hexJumping = false
+	m.statusMessage = ""
+	m.moveHexCursor(0)
+}
+
+// moveHexCursor 按字节移动十六进制视图的光标，并滚动使光标所在行可见
+func (m *model) moveHexCursor(delta int) {
+	m.hexCursor += delta
+	if m.hexCursor > len(m.detailRaw)-1 {
+		m.hexCursor = len(m.detailRaw) - 1
+	}
+	if m.hexCursor < 0 {
+		m.hexCursor = 0
+	}
+
+	row := m.hexCursor - m.hexCursor%utils.HexDumpWidth
+	if row < m.hexOffset {
+		m.hexOffset = row
+	} else if row >= m.hexOffset+hexPageRows*utils.HexDumpWidth {
+		m.hexOffset = row - (hexPageRows-1)*utils.HexDumpWidth
+	}
+}
+
+// updateHexView 处理十六进制视图的按键
+func (m model) updateHexView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.hexJumping {
+		return m.updateHexJump(msg)
+	}
+
+	const page = hexPageRows * utils.HexDumpWidth
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		// 返回文本视图
+		m.detailHexMode = false
+		m.statusMessage = ""
+	case tea.KeyDown:
+		m.moveHexCursor(utils.HexDumpWidth)
+	case tea.KeyUp:
+		m.moveHexCursor(-utils.HexDumpWidth)
+	case tea.KeyPgDown, tea.KeyCtrlF, tea.KeySpace:
+		m.moveHexCursor(page)
+	case tea.KeyPgUp, tea.KeyCtrlB:
+		m.moveHexCursor(-page)
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "j":
+			m.moveHexCursor(utils.HexDumpWidth)
+		case "k":
+			m.moveHexCursor(-utils.HexDumpWidth)
+		case "l":
+			m.moveHexCursor(1)
+		case "h":
+			m.moveHexCursor(-1)
+		case "g":
+			m.moveHexCursor(-m.hexCursor)
+		case "G":
+			m.moveHexCursor(len(m.detailRaw))
+		case ":":
+			// 输入要跳转的偏移
+			m.hexJumping = true
+			m.hexJumpInput = ""
+			m.statusMessage = ""
+		case "x":
+			// 返回文本视图
+			m.detailHexMode = false
+			m.statusMessage = ""
+		}
+	}
+
+	return m, nil
+}
+
+// updateHexJump 处理跳转偏移的输入，支持十进制和 0x 开头的十六进制
+func (m model) updateHexJump(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.hexJumping = false
+	case tea.KeyBackspace:
+		if len(m.hexJumpInput) > 0 {
+			m.hexJumpInput = m.hexJumpInput[:len(m.hexJumpInput)-1]
+		}
+	case tea.KeyEnter:
+		m.hexJumping = false
+		input := strings.TrimSpace(m.hexJumpInput)
+		offset, err := strconv.ParseInt(input, 0, 64)
+		if err != nil || offset < 0 {
+			m.statusMessage = fmt.Sprintf("Invalid offset %q", input)
+			return m, nil
+		}
+		if offset >= int64(len(m.detailRaw)) {
+			m.statusMessage = fmt.Sprintf("Offset %s is beyond the end of the value (%d bytes)", input, len(m.detailRaw))
+			return m, nil
+		}
+		m.moveHexCursor(int(offset) - m.hexCursor)
+	case tea.KeyRunes:
+		m.hexJumpInput += string(msg.Runes)
+	}
+
+	return m, nil
+}
+
+// formatValue 按当前显示编码渲染值并记录格式信息，只有可读文本才做格式化
+func (m *model) formatValue(value []byte) string {
 	if len(value) == 0 {
 		m.valueFormat = utils.FormatPlainText
+		m.detailEncoding = utils.EncodingUTF8
 		return "<empty>"
 	}
 
-	formatted, format := utils.FormatContent(value)
+	text, enc := utils.DisplayValue(value, m.displayEncoding)
+	m.detailEncoding = enc
+	if enc != utils.EncodingUTF8 {
+		m.valueFormat = utils.FormatPlainText
+		return text
+	}
+
+	formatted, format := utils.FormatContent(text)
 	m.valueFormat = format
 	return formatted
 }
 
-// formatJSON 兼容性方法，使用新的 formatValue
-func (m model) formatJSON(value string) string {
-	formatted, _ := utils.FormatContent(value)
-	return formatted
+// displayKey 按当前显示编码渲染key
+func (m model) displayKey(key []byte) string {
+	if len(key) == 0 {
+		return "<empty key>"
+	}
+	return utils.DisplayKey(key, m.displayEncoding)
+}
+
+// checkDetailLoaded 检查详情的 value 是否已成功读取，读取中、读取失败或key不存在时
+// 在状态栏提示原因，避免用空内容覆盖存储中的 value
+func (m *model) checkDetailLoaded() bool {
+	switch {
+	case m.detailLoading:
+		m.statusMessage = "Value is still loading"
+		return false
+	case m.detailLoadErr != "":
+		m.statusMessage = fmt.Sprintf("Cannot edit: %s", m.detailLoadErr)
+		return false
+	}
+	return true
+}
+
+// toggleEncoding 切换显示编码，详情中已读取的 value 按新编码重新渲染
+func (m *model) toggleEncoding() {
+	m.displayEncoding = utils.NextEncoding(m.displayEncoding)
+	m.statusMessage = fmt.Sprintf("Display encoding: %s", utils.GetEncodingName(m.displayEncoding))
+	if m.mode != modeDetail || m.detailLoading || m.detailRaw == nil {
+		return
+	}
+	m.detailValue = m.formatValue(m.detailRaw)
+	m.detailLines = strings.Split(m.detailValue, "\n")
+	m.detailCursorLine = 0
+	m.detailCursorCol = 0
 }
 
 // updateEdit 处理编辑模式的按键（Vi风格）
@@ -630,8 +1130,11 @@ func (m model) updateEditCommand(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	// 处理特殊按键组合保存 (Ctrl+S 或 ZZ)
 	if msg.Type == tea.KeyCtrlS {
+		if !m.checkDetailLoaded() {
+			return m, nil
+		}
 		newValue := strings.Join(m.editLines, "\n")
-		return m, m.saveKeyCmd(newValue, false)
+		return m.guardWrite("save", m.detailKey, m.saveKeyCmd(newValue, false))
 	}
 
 	return m, nil
@@ -657,14 +1160,20 @@ func (m model) updateEditCommandLine(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch cmd {
 		case ":w":
 			// 保存文件，保持在编辑模式
+			if !m.checkDetailLoaded() {
+				return m, nil
+			}
 			newValue := strings.Join(m.editLines, "\n")
 
-			return m, m.saveKeyCmd(newValue, false)
+			return m.guardWrite("save", m.detailKey, m.saveKeyCmd(newValue, false))
 		case ":x", ":wq":
 			// 保存并退出
+			if !m.checkDetailLoaded() {
+				return m, nil
+			}
 			newValue := strings.Join(m.editLines, "\n")
 			// 先保存，然后在保存成功后会自动返回详细视图
-			return m, m.saveKeyCmd(newValue, true)
+			return m.guardWrite("save", m.detailKey, m.saveKeyCmd(newValue, true))
 		case ":q":
 			// 退出（不保存）
 			m.mode = modeDetail
@@ -793,13 +1302,21 @@ func (m model) updateEditInsert(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// loadDetailCmd 读取详情模式要显示的 value
+func (m model) loadDetailCmd(key []byte) tea.Cmd {
+	return func() tea.Msg {
+		value, err := m.kvClient.Get(m.ctx, key)
+		return detailLoadedMsg{key: key, value: value, found: value != nil, err: err}
+	}
+}
+
 // deleteCurrentKeyCmd 删除详情模式中当前的key
 func (m model) deleteCurrentKeyCmd() tea.Cmd {
 	key := m.detailKey
 	return func() tea.Msg {
-		err := m.kvClient.Delete(m.ctx, []byte(key))
+		err := m.kvClient.Delete(m.ctx, key)
 		if err != nil {
-			return searchResultMsg{results: nil, err: err}
+			return deleteErrorMsg{key: key, err: err}
 		}
 		// 删除成功，返回搜索视图并刷新结果
 		return deleteSuccessMsg{key: key}
@@ -813,9 +1330,9 @@ func (m model) deleteSelectedKeyCmd() tea.Cmd {
 	}
 	key := m.results[m.selectedItem].Key
 	return func() tea.Msg {
-		err := m.kvClient.Delete(m.ctx, []byte(key))
+		err := m.kvClient.Delete(m.ctx, key)
 		if err != nil {
-			return searchResultMsg{results: nil, err: err}
+			return deleteErrorMsg{key: key, err: err}
 		}
 		// 删除成功，刷新搜索结果
 		return deleteSuccessMsg{key: key}
@@ -825,78 +1342,104 @@ func (m model) deleteSelectedKeyCmd() tea.Cmd {
 // saveKeyCmd 保存编辑后的value
 func (m model) saveKeyCmd(newValue string, exitToDetail bool) tea.Cmd {
 	key := m.detailKey
+	enc := m.detailEncoding
 	return func() tea.Msg {
+		// 按打开时的显示编码把编辑后的文本还原为原始字节
+		value, err := utils.DecodeDisplay(newValue, enc)
+		if err != nil {
+			return saveErrorMsg{key: key, err: fmt.Errorf("invalid %s value: %v", utils.GetEncodingName(enc), err)}
+		}
 
-		err := m.kvClient.Put(m.ctx, []byte(key), []byte(newValue))
+		err = m.kvClient.Put(m.ctx, key, value)
 
 		if err != nil {
 			return saveErrorMsg{key: key, err: err}
 		}
-		return saveSuccessMsg{key: key, value: newValue, exitToDetail: exitToDetail}
+		return saveSuccessMsg{key: key, value: value, exitToDetail: exitToDetail}
 	}
 }
 
-func (m model) searchCmd() tea.Cmd {
-	if len(m.input) == 0 {
-		// 如果没有输入，显示所有key（不限制前缀）
-		return func() tea.Msg {
-			keys, vals, err := m.kvClient.ScanAllKeys(m.ctx, 50)
-			if err != nil {
-				return searchResultMsg{results: nil, err: err}
-			}
+// searchPageSize 搜索结果每页加载的数量
+const searchPageSize = 50
 
-			results := make([]KeyValue, len(keys))
-			for i, key := range keys {
-				// 存储完整的值，用于详细视图
-				val := string(vals[i])
-				if len(val) == 0 {
-					val = "" // 空值直接设为空字符串
-				}
+// searchCmd 开始新的一次搜索，之前还未返回的搜索和分页请求的结果都会被丢弃。
+// 会修改搜索状态，调用方需要在返回 m 之前调用
+func (m *model) searchCmd() tea.Cmd {
+	m.searchGen++
+	m.searching = true
+	m.loadingMore = false
+	gen := m.searchGen
+	input := m.input
+	cluster, keyspace := m.clusterName, m.keyspace
+	ctx, kvClient := m.ctx, m.kvClient
 
-				keyStr := string(key)
-				if len(keyStr) == 0 {
-					keyStr = "<empty key>"
-				}
-				results[i] = KeyValue{
-					Key:   keyStr,
-					Value: val,
-				}
-			}
-			return searchResultMsg{results: results, err: nil}
+	return func() tea.Msg {
+		// 输入作为前缀，支持 0x 十六进制和 \x00 转义；没有输入时扫描所有key
+		prefix, err := utils.ParseKeyInput(input)
+		if err != nil {
+			return searchResultMsg{gen: gen, cluster: cluster, keyspace: keyspace, query: input, err: err}
+		}
+		page, err := dao.KeyOnlyScanPage(ctx, kvClient, prefix, dao.PrefixEnd(prefix), searchPageSize)
+		if err != nil {
+			return searchResultMsg{gen: gen, cluster: cluster, keyspace: keyspace, query: input, err: err}
+		}
+
+		return searchResultMsg{
+			gen:      gen,
+			cluster:  cluster,
+			keyspace: keyspace,
+			query:    input,
+			results:  toKeyValues(page.Keys, page.Vals),
+			hasMore:  page.HasMore,
+			next:     page.Next,
 		}
 	}
+}
 
-	m.searching = true
+// loadMoreCmd 从上一页结束的位置继续加载下一页搜索结果
+func (m model) loadMoreCmd() tea.Cmd {
 	input := m.input
+	next := m.nextKey
+	gen := m.searchGen
+	cluster, keyspace := m.clusterName, m.keyspace
 
 	return func() tea.Msg {
-		// 直接使用用户输入的内容作为前缀，不添加任何前缀
-		keys, vals, err := m.kvClient.ScanWithRealPrefix(m.ctx, []byte(input), 50)
-
+		prefix, err := utils.ParseKeyInput(input)
 		if err != nil {
-			return searchResultMsg{results: nil, err: err}
+			return searchResultMsg{gen: gen, cluster: cluster, keyspace: keyspace, query: input, appendPage: true, err: err}
+		}
+		page, err := dao.KeyOnlyScanPage(m.ctx, m.kvClient, next, dao.PrefixEnd(prefix), searchPageSize)
+		if err != nil {
+			return searchResultMsg{gen: gen, cluster: cluster, keyspace: keyspace, query: input, appendPage: true, err: err}
 		}
 
-		results := make([]KeyValue, len(keys))
-		for i, key := range keys {
-			// 存储完整的值，用于详细视图
-			val := string(vals[i])
-			if len(val) == 0 {
-				val = "" // 空值直接设为空字符串
-			}
-
-			keyStr := string(key)
-			if len(keyStr) == 0 {
-				keyStr = "<empty key>"
-			}
-			results[i] = KeyValue{
-				Key:   keyStr,
-				Value: val,
-			}
+		return searchResultMsg{
+			gen:        gen,
+			cluster:    cluster,
+			keyspace:   keyspace,
+			query:      input,
+			results:    toKeyValues(page.Keys, page.Vals),
+			hasMore:    page.HasMore,
+			next:       page.Next,
+			appendPage: true,
 		}
+	}
+}
 
-		return searchResultMsg{results: results, err: nil}
+// toKeyValues 将扫描结果转换为列表项，vals 为空时只填充key
+func toKeyValues(keys, vals [][]byte) []KeyValue {
+	results := make([]KeyValue, len(keys))
+	for i, key := range keys {
+		var val []byte
+		if i < len(vals) {
+			val = vals[i]
+		}
+		results[i] = KeyValue{
+			Key:   key,
+			Value: val,
+		}
 	}
+	return results
 }
 
 // updateAdd 处理添加模式的按键
@@ -946,7 +1489,7 @@ func (m model) updateAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyCtrlS:
 		// Ctrl+S 保存键值对
 		if len(strings.TrimSpace(m.addKey)) > 0 {
-			return m, m.addKeyValueCmd()
+			return m.guardWrite("add", []byte(strings.TrimSpace(m.addKey)), m.addKeyValueCmd())
 		}
 		return m, nil
 
@@ -1008,7 +1551,7 @@ func (m model) addKeyValueCmd() tea.Cmd {
 	return func() tea.Msg {
 		err := m.kvClient.Put(m.ctx, []byte(key), []byte(value))
 		if err != nil {
-			return saveErrorMsg{key: key, err: err}
+			return saveErrorMsg{key: []byte(key), err: err}
 		}
 		return addSuccessMsg{key: key, value: value}
 	}
@@ -1041,11 +1584,178 @@ func (m model) executeSelectedCommand() (tea.Model, tea.Cmd) {
 		m.addCursor = 0
 		m.statusMessage = ""
 		return m, nil
+	case "/keyspace":
+		// 切换到 keyspace 选择模式，并从 PD 加载列表
+		m.mode = modeKeyspace
+		m.keyspaces = nil
+		m.selectedKeyspace = 0
+		m.keyspaceOffset = 0
+		m.loadingKeyspaces = true
+		m.statusMessage = ""
+		return m, m.listKeyspacesCmd()
+	case "/connect":
+		// 切换到集群选择模式，默认选中当前集群
+		m.mode = modeConnect
+		m.selectedCluster = 0
+		m.clusterOffset = 0
+		for i, name := range m.clusterNames {
+			if name == m.clusterName {
+				m.selectedCluster = i
+				if i >= 10 {
+					m.clusterOffset = i - 9
+				}
+				break
+			}
+		}
+		m.statusMessage = ""
+		return m, nil
 	default:
 		return m, nil
 	}
 }
 
+// updateKeyspace 处理 keyspace 选择模式的按键
+func (m model) updateKeyspace(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		// 返回Main模式
+		m.mode = modeMain
+		m.isInCommand = true
+		m.commandPrefix = ""
+		m.filterCommands()
+		m.statusMessage = ""
+		return m, nil
+
+	case tea.KeyUp:
+		if m.selectedKeyspace > 0 {
+			m.selectedKeyspace--
+			if m.selectedKeyspace < m.keyspaceOffset {
+				m.keyspaceOffset = m.selectedKeyspace
+			}
+		}
+
+	case tea.KeyDown:
+		if m.selectedKeyspace < len(m.keyspaces)-1 {
+			m.selectedKeyspace++
+			if m.selectedKeyspace >= m.keyspaceOffset+10 {
+				m.keyspaceOffset = m.selectedKeyspace - 9
+			}
+		}
+
+	case tea.KeyEnter:
+		if m.loadingKeyspaces || m.selectedKeyspace >= len(m.keyspaces) {
+			return m, nil
+		}
+		m.statusMessage = "Switching keyspace..."
+		return m, m.switchKeyspaceCmd(m.keyspaces[m.selectedKeyspace].Name)
+	}
+
+	return m, nil
+}
+
+// listKeyspacesCmd 从 PD 加载 keyspace 列表
+func (m model) listKeyspacesCmd() tea.Cmd {
+	return func() tea.Msg {
+		lister, ok := m.kvClient.(dao.KeyspaceLister)
+		if !ok {
+			return keyspaceListMsg{err: fmt.Errorf("listing keyspaces is not supported by this storage")}
+		}
+		keyspaces, err := lister.ListKeyspaces(m.ctx)
+		return keyspaceListMsg{keyspaces: keyspaces, err: err}
+	}
+}
+
+// switchKeyspaceCmd 连接到指定的 keyspace
+func (m model) switchKeyspaceCmd(keyspace string) tea.Cmd {
+	if m.keyspaceSwitcher == nil {
+		return nil
+	}
+	switcher := m.keyspaceSwitcher
+	cluster := m.clusterName
+	return func() tea.Msg {
+		kvClient, err := switcher(m.ctx, keyspace)
+		return keyspaceSwitchMsg{cluster: cluster, keyspace: keyspace, kvClient: kvClient, err: err}
+	}
+}
+
+// updateConnect 处理集群选择模式的按键
+func (m model) updateConnect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		// 返回Main模式
+		m.mode = modeMain
+		m.isInCommand = true
+		m.commandPrefix = ""
+		m.filterCommands()
+		m.statusMessage = ""
+		return m, nil
+
+	case tea.KeyUp:
+		if m.selectedCluster > 0 {
+			m.selectedCluster--
+			if m.selectedCluster < m.clusterOffset {
+				m.clusterOffset = m.selectedCluster
+			}
+		}
+
+	case tea.KeyDown:
+		if m.selectedCluster < len(m.clusterNames)-1 {
+			m.selectedCluster++
+			if m.selectedCluster >= m.clusterOffset+10 {
+				m.clusterOffset = m.selectedCluster - 9
+			}
+		}
+
+	case tea.KeyEnter:
+		if m.connecting || m.selectedCluster >= len(m.clusterNames) {
+			return m, nil
+		}
+		name := m.clusterNames[m.selectedCluster]
+		m.connecting = true
+		m.statusMessage = fmt.Sprintf("Connecting to '%s'...", name)
+		return m, m.connectClusterCmd(name)
+	}
+
+	return m, nil
+}
+
+// connectClusterCmd 连接到指定的集群
+func (m model) connectClusterCmd(name string) tea.Cmd {
+	if m.clusterConnector == nil {
+		return nil
+	}
+	connector := m.clusterConnector
+	return func() tea.Msg {
+		cluster, err := connector(m.ctx, name)
+		return clusterConnectMsg{name: name, cluster: cluster, err: err}
+	}
+}
+
+// refreshCommands 根据当前连接支持的功能重建命令列表
+func (m *model) refreshCommands() {
+	m.commandList = []Command{
+		{Name: "/search", Description: "Search keys by prefix"},
+	}
+	if !m.readOnly() {
+		m.commandList = append(m.commandList, Command{Name: "/add", Description: "Add new key-value pair"})
+	}
+	if m.keyspaceSwitcher != nil {
+		m.commandList = append(m.commandList, Command{Name: "/keyspace", Description: "Switch to another keyspace"})
+	}
+	if m.clusterConnector != nil && len(m.clusterNames) > 0 {
+		m.commandList = append(m.commandList, Command{Name: "/connect", Description: "Connect to another cluster"})
+	}
+	m.filterCommands()
+	m.selectedCommand = 0
+	m.commandOffset = 0
+}
+
 // filterCommands 根据输入过滤命令列表
 func (m *model) filterCommands() {
 	m.filteredCommands = []Command{}
@@ -1057,6 +1767,9 @@ func (m *model) filterCommands() {
 }
 
 func (m model) View() string {
+	if m.confirm != nil {
+		return m.viewConfirm()
+	}
 	switch m.mode {
 	case modeMain:
 		return m.viewMain()
@@ -1068,6 +1781,10 @@ func (m model) View() string {
 		return m.viewEdit()
 	case modeAdd:
 		return m.viewAdd()
+	case modeKeyspace:
+		return m.viewKeyspace()
+	case modeConnect:
+		return m.viewConnect()
 	default:
 		return m.viewMain()
 	}
@@ -1077,11 +1794,7 @@ func (m model) viewMain() string {
 	var s strings.Builder
 
 	// 标题
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		PaddingBottom(1).
-		Render("🔍 TiKV Key Explorer")
+	title := m.renderTitle("🔍 TiKV Key Explorer")
 	s.WriteString(title + "\n")
 
 	// 输入框区域
@@ -1101,6 +1814,15 @@ func (m model) viewMain() string {
 	// 显示命令列表
 	m.renderCommandList(&s)
 
+	// 状态消息
+	if m.statusMessage != "" {
+		statusStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#10b981")).
+			MarginTop(1)
+		s.WriteString(statusStyle.Render(m.statusMessage) + "\n")
+	}
+
 	// 帮助信息
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#626262")).
@@ -1123,11 +1845,7 @@ func (m model) viewSearch() string {
 	var s strings.Builder
 
 	// 标题
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		PaddingBottom(1).
-		Render("🔍 TiKV Key Explorer")
+	title := m.renderTitle("🔍 TiKV Key Explorer")
 	s.WriteString(title + "\n")
 
 	// 输入框区域（固定高度）
@@ -1163,6 +1881,13 @@ func (m model) viewSearch() string {
 		m.renderResults(&s)
 	}
 
+	// 状态栏
+	if status := m.searchStatus(); status != "" {
+		statusStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#10b981"))
+		s.WriteString(statusStyle.Render(status) + "\n")
+	}
+
 	// 帮助信息
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#626262")).
@@ -1170,9 +1895,12 @@ func (m model) viewSearch() string {
 
 	var helpText string
 	if len(m.input) > 0 || len(m.results) > 0 {
-		helpText = "• ↑/↓ navigate • Enter view • dd delete • Esc to main"
+		helpText = "• ↑/↓ navigate • Enter view • dd delete • Ctrl+T encoding (" + utils.GetEncodingName(m.displayEncoding) + ") • Esc to main"
+		if m.readOnly() {
+			helpText = "• ↑/↓ navigate • Enter view • Ctrl+T encoding (" + utils.GetEncodingName(m.displayEncoding) + ") • Esc to main"
+		}
 	} else {
-		helpText = "• Start typing to search • Esc to main"
+		helpText = "• Start typing to search (0x.. for hex, \\x00 escapes) • Esc to main"
 	}
 
 	s.WriteString("\n" + help.Render(helpText))
@@ -1202,10 +1930,14 @@ func (m model) renderResults(s *strings.Builder) {
 	}
 
 	// 结果标题
+	count := fmt.Sprintf("%d", len(m.results))
+	if m.hasMore {
+		count += "+"
+	}
 	resultsTitle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("#04B575")).
-		Render(fmt.Sprintf("---------------------- results (%d) ----------------------", len(m.results)))
+		Render(fmt.Sprintf("---------------------- results (%s) ----------------------", count))
 	s.WriteString(resultsTitle + "\n")
 
 	// 显示10行结果（带滚动）
@@ -1234,9 +1966,9 @@ func (m model) renderResults(s *strings.Builder) {
 		}
 
 		// 只显示 key，不显示 value
-		keyText := result.Key
-		if len(keyText) > 120 {
-			keyText = keyText[:117] + "..."
+		keyText := m.displayKey(result.Key)
+		if runes := []rune(keyText); len(runes) > 120 {
+			keyText = string(runes[:117]) + "..."
 		}
 
 		line := keyText
@@ -1245,7 +1977,7 @@ func (m model) renderResults(s *strings.Builder) {
 
 	// 滚动指示器
 	if len(m.results) > maxDisplay {
-		scrollInfo := fmt.Sprintf("[%d-%d of %d]", start+1, end, len(m.results))
+		scrollInfo := fmt.Sprintf("[%d-%d of %s]", start+1, end, count)
 		scrollStyle := lipgloss.NewStyle().
 			Italic(true).
 			Foreground(lipgloss.Color("#6b7280"))
@@ -1253,21 +1985,38 @@ func (m model) renderResults(s *strings.Builder) {
 	}
 }
 
+// searchStatus 搜索模式状态栏：已加载数量、是否还有更多结果以及其他提示信息
+func (m model) searchStatus() string {
+	var parts []string
+	if len(m.results) > 0 {
+		switch {
+		case m.loadingMore:
+			parts = append(parts, fmt.Sprintf("%d loaded, loading more...", len(m.results)))
+		case m.hasMore:
+			parts = append(parts, fmt.Sprintf("%d loaded, more available", len(m.results)))
+		default:
+			parts = append(parts, fmt.Sprintf("%d loaded", len(m.results)))
+		}
+	}
+	if m.statusMessage != "" {
+		parts = append(parts, m.statusMessage)
+	}
+	return strings.Join(parts, " • ")
+}
+
 func (m model) viewDetail() string {
 	var s strings.Builder
 
 	// 标题
 	var titleText string
-	if m.detailCommandMode {
+	if m.detailHexMode {
+		titleText = "📝 Detail View -- HEX --"
+	} else if m.detailCommandMode {
 		titleText = "📝 Detail View -- NORMAL --"
 	} else {
 		titleText = "📝 Detail View -- VIEW --"
 	}
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		PaddingBottom(1).
-		Render(titleText)
+	title := m.renderTitle(titleText)
 	s.WriteString(title + "\n")
 
 	// Key 显示
@@ -1276,7 +2025,7 @@ func (m model) viewDetail() string {
 		Foreground(lipgloss.Color("#10b981")).
 		PaddingBottom(1)
 	s.WriteString(keyStyle.Render("Key:") + "\n")
-	s.WriteString(m.detailKey + "\n\n")
+	s.WriteString(m.displayKey(m.detailKey) + "\n\n")
 
 	// Value 显示（显示检测到的格式）
 	valueStyle := lipgloss.NewStyle().
@@ -1284,8 +2033,19 @@ func (m model) viewDetail() string {
 		Foreground(lipgloss.Color("#10b981")).
 		PaddingBottom(1)
 	formatName := utils.GetFormatName(m.valueFormat)
+	if m.detailHexMode {
+		formatName = fmt.Sprintf("hex dump, %d bytes", len(m.detailRaw))
+	} else if m.detailEncoding != utils.EncodingUTF8 {
+		// 二进制数据显示实际使用的编码
+		formatName = utils.GetEncodingName(m.detailEncoding)
+	}
 	s.WriteString(valueStyle.Render(fmt.Sprintf("Value (%s):", formatName)) + "\n")
 
+	if m.detailHexMode {
+		s.WriteString(m.viewHexDump())
+		return s.String()
+	}
+
 	// JSON 内容显示区域 - 不使用语法高亮
 	jsonStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -1324,15 +2084,29 @@ func (m model) viewDetail() string {
 
 	s.WriteString(jsonStyle.Render(jsonContent.String()) + "\n\n")
 
+	// 状态消息
+	if m.statusMessage != "" {
+		statusStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#10b981"))
+		s.WriteString(statusStyle.Render(m.statusMessage) + "\n")
+	}
+
 	// 帮助信息
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#626262"))
 
 	var helpText string
-	if m.detailCommandMode {
-		helpText = "• Esc return • dd delete • i edit • v view mode"
+	if m.readOnly() {
+		if m.detailCommandMode {
+			helpText = "• Esc return • v view mode • x hex view • Ctrl+T encoding (" + utils.GetEncodingName(m.displayEncoding) + ")"
+		} else {
+			helpText = "• Esc return • c command mode • x hex view • Ctrl+T encoding (" + utils.GetEncodingName(m.displayEncoding) + ")"
+		}
+	} else if m.detailCommandMode {
+		helpText = "• Esc return • dd delete • i edit • v view mode • x hex view • Ctrl+T encoding (" + utils.GetEncodingName(m.displayEncoding) + ")"
 	} else {
-		helpText = "• Esc return • c command mode • i edit"
+		helpText = "• Esc return • c command mode • i edit • x hex view • Ctrl+T encoding (" + utils.GetEncodingName(m.displayEncoding) + ")"
 	}
 
 	s.WriteString(help.Render(helpText))
@@ -1340,6 +2114,125 @@ func (m model) viewDetail() string {
 	return s.String()
 }
 
+// viewHexDump 渲染详情的十六进制视图，光标所在行高亮
+func (m model) viewHexDump() string {
+	var s strings.Builder
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6b7280")).
+		Padding(1)
+	cursorStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("#3b82f6")).
+		Foreground(lipgloss.Color("#ffffff"))
+
+	lines := utils.HexDump(m.detailRaw, m.hexOffset, hexPageRows)
+	cursorRow := (m.hexCursor - m.hexOffset) / utils.HexDumpWidth
+	for i, line := range lines {
+		if i == cursorRow {
+			lines[i] = cursorStyle.Render(line)
+		}
+	}
+	if len(lines) == 0 {
+		lines = []string{"<empty value>"}
+	}
+	s.WriteString(boxStyle.Render(strings.Join(lines, "\n")) + "\n")
+
+	// 位置信息
+	infoStyle := lipgloss.NewStyle().
+		Italic(true).
+		Foreground(lipgloss.Color("#6b7280"))
+	if len(m.detailRaw) > 0 {
+		end := m.hexOffset + hexPageRows*utils.HexDumpWidth
+		if end > len(m.detailRaw) {
+			end = len(m.detailRaw)
+		}
+		s.WriteString(infoStyle.Render(fmt.Sprintf("offset 0x%08x (%d) • showing 0x%x-0x%x of %d bytes",
+			m.hexCursor, m.hexCursor, m.hexOffset, end-1, len(m.detailRaw))) + "\n")
+	}
+	s.WriteString("\n")
+
+	// 跳转输入或状态消息
+	if m.hexJumping {
+		promptStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#fbbf24"))
+		s.WriteString(promptStyle.Render("Jump to offset: "+m.hexJumpInput+"█") + "\n")
+	} else if m.statusMessage != "" {
+		statusStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#10b981"))
+		s.WriteString(statusStyle.Render(m.statusMessage) + "\n")
+	}
+
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262"))
+	if m.hexJumping {
+		s.WriteString(help.Render("• Enter offset (decimal or 0x hex) • Enter to jump • Esc to cancel"))
+	} else {
+		s.WriteString(help.Render("• j/k scroll • Ctrl+F/Ctrl+B page • g/G top/bottom • : jump to offset • x/Esc text view"))
+	}
+
+	return s.String()
+}
+
+// renderTitle 渲染视图标题，并在标题后附加当前连接信息
+func (m model) renderTitle(text string) string {
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7D56F4")).
+		Render(text)
+
+	// 醒目地显示当前集群名称，避免在多个集群间误操作
+	if m.clusterName != "" {
+		clusterStyle := lipgloss.NewStyle().
+			Bold(true).
+			Background(lipgloss.Color("#f59e0b")).
+			Foreground(lipgloss.Color("#000000")).
+			Padding(0, 1)
+		title += "  " + clusterStyle.Render(m.clusterName)
+	}
+
+	if m.readOnly() {
+		readOnlyStyle := lipgloss.NewStyle().
+			Bold(true).
+			Background(lipgloss.Color("#ef4444")).
+			Foreground(lipgloss.Color("#ffffff")).
+			Padding(0, 1)
+		title += "  " + readOnlyStyle.Render("READ-ONLY")
+	}
+
+	if info := m.connectionInfo(); info != "" {
+		infoStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6b7280"))
+		title += "  " + infoStyle.Render(info)
+	}
+
+	return lipgloss.NewStyle().PaddingBottom(1).Render(title)
+}
+
+// readOnlyMessage 只读模式下尝试修改数据时的提示
+const readOnlyMessage = "Read-only mode: adding, editing and deleting keys is disabled"
+
+// readOnly 当前连接是否为只读，只读时隐藏添加、编辑和删除功能
+func (m model) readOnly() bool {
+	return dao.IsReadOnly(m.kvClient)
+}
+
+// connectionInfo 当前连接的描述信息，如 API 版本
+func (m model) connectionInfo() string {
+	var parts []string
+	if m.apiVersion != "" {
+		parts = append(parts, "API "+m.apiVersion)
+	}
+	if m.keyspace != "" {
+		parts = append(parts, "keyspace: "+m.keyspace)
+	} else if m.apiVersion == "V2" {
+		parts = append(parts, "keyspace: <default>")
+	}
+	return strings.Join(parts, " | ")
+}
+
 // renderCommandList 渲染命令列表
 func (m model) renderCommandList(s *strings.Builder) {
 	if len(m.filteredCommands) == 0 {
@@ -1403,11 +2296,7 @@ func (m model) viewAdd() string {
 	var s strings.Builder
 
 	// 标题
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		PaddingBottom(1).
-		Render("🔍 TiKV Key Explorer")
+	title := m.renderTitle("🔍 TiKV Key Explorer")
 	s.WriteString(title + "\n")
 
 	// 显示当前步骤信息
@@ -1512,11 +2401,7 @@ func (m model) viewEdit() string {
 	var s strings.Builder
 
 	// 标题
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		PaddingBottom(1).
-		Render("✏️  Edit Mode")
+	title := m.renderTitle("✏️  Edit Mode")
 	s.WriteString(title + "\n")
 
 	// Key 显示
@@ -1525,7 +2410,7 @@ func (m model) viewEdit() string {
 		Foreground(lipgloss.Color("#10b981")).
 		PaddingBottom(1)
 	s.WriteString(keyStyle.Render("Key:") + "\n")
-	s.WriteString(m.detailKey + "\n\n")
+	s.WriteString(m.displayKey(m.detailKey) + "\n\n")
 
 	// 编辑区域标题
 	valueStyle := lipgloss.NewStyle().
@@ -1613,3 +2498,184 @@ func (m model) viewEdit() string {
 
 	return s.String()
 }
+
+// viewKeyspace 显示 keyspace 选择模式
+func (m model) viewKeyspace() string {
+	var s strings.Builder
+
+	// 标题
+	title := m.renderTitle("🗂  Keyspaces")
+	s.WriteString(title + "\n")
+
+	if m.loadingKeyspaces {
+		loading := lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color("#626262")).
+			Render("Loading keyspaces...")
+		s.WriteString(loading + "\n")
+	} else if len(m.keyspaces) == 0 {
+		noKeyspace := lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color("#626262")).
+			Render("No keyspaces found")
+		s.WriteString(noKeyspace + "\n")
+	} else {
+		// keyspace 列表标题
+		listTitle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#04B575")).
+			Render(fmt.Sprintf("Keyspaces (%d)", len(m.keyspaces)))
+		s.WriteString(listTitle + "\n")
+
+		// 显示10行（带滚动）
+		maxDisplay := 10
+		start := m.keyspaceOffset
+		end := start + maxDisplay
+		if end > len(m.keyspaces) {
+			end = len(m.keyspaces)
+		}
+
+		for i := start; i < end; i++ {
+			ks := m.keyspaces[i]
+			var style lipgloss.Style
+
+			if i == m.selectedKeyspace {
+				style = lipgloss.NewStyle().
+					Background(lipgloss.Color("#3b82f6")).
+					Foreground(lipgloss.Color("#ffffff")).
+					Padding(0, 1)
+			} else {
+				style = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#9ca3af")).
+					Padding(0, 1)
+			}
+
+			// 标记当前 keyspace
+			current := " "
+			if ks.Name == m.keyspace {
+				current = "*"
+			}
+			line := fmt.Sprintf("%s %-6d %-32s %s", current, ks.ID, ks.Name, ks.State)
+			s.WriteString(style.Render(line) + "\n")
+		}
+
+		// 滚动指示器
+		if len(m.keyspaces) > maxDisplay {
+			scrollInfo := fmt.Sprintf("[%d-%d of %d]", start+1, end, len(m.keyspaces))
+			scrollStyle := lipgloss.NewStyle().
+				Italic(true).
+				Foreground(lipgloss.Color("#6b7280"))
+			s.WriteString(scrollStyle.Render(scrollInfo) + "\n")
+		}
+	}
+
+	// 状态消息
+	if m.statusMessage != "" {
+		statusStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#10b981")).
+			MarginTop(1)
+		s.WriteString(statusStyle.Render(m.statusMessage) + "\n")
+	}
+
+	// 帮助信息
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		MarginTop(1)
+	s.WriteString("\n" + help.Render("• ↑/↓ select keyspace • Enter to switch • Esc to main"))
+
+	// 模式指示器
+	modeIndicator := "---Keyspace---"
+	modeStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7D56F4")).
+		MarginTop(1)
+	s.WriteString("\n" + modeStyle.Render(modeIndicator))
+
+	return s.String()
+}
+
+// viewConnect 显示集群选择模式
+func (m model) viewConnect() string {
+	var s strings.Builder
+
+	// 标题
+	title := m.renderTitle("🔌 Connect")
+	s.WriteString(title + "\n")
+
+	// 集群列表标题
+	listTitle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#04B575")).
+		Render(fmt.Sprintf("Clusters (%d)", len(m.clusterNames)))
+	s.WriteString(listTitle + "\n")
+
+	// 显示10行（带滚动）
+	maxDisplay := 10
+	start := m.clusterOffset
+	end := start + maxDisplay
+	if end > len(m.clusterNames) {
+		end = len(m.clusterNames)
+	}
+
+	for i := start; i < end; i++ {
+		name := m.clusterNames[i]
+		var style lipgloss.Style
+
+		if i == m.selectedCluster {
+			style = lipgloss.NewStyle().
+				Background(lipgloss.Color("#3b82f6")).
+				Foreground(lipgloss.Color("#ffffff")).
+				Padding(0, 1)
+		} else {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#9ca3af")).
+				Padding(0, 1)
+		}
+
+		// 标记当前集群和已有搜索结果的集群
+		current := " "
+		if name == m.clusterName {
+			current = "*"
+		}
+		line := fmt.Sprintf("%s %s", current, name)
+		if state, ok := m.clusterStates[name]; ok && name != m.clusterName && len(state.results) > 0 {
+			line += fmt.Sprintf("  (%d results for '%s')", len(state.results), state.input)
+		}
+		s.WriteString(style.Render(line) + "\n")
+	}
+
+	// 滚动指示器
+	if len(m.clusterNames) > maxDisplay {
+		scrollInfo := fmt.Sprintf("[%d-%d of %d]", start+1, end, len(m.clusterNames))
+		scrollStyle := lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color("#6b7280"))
+		s.WriteString(scrollStyle.Render(scrollInfo) + "\n")
+	}
+
+	// 状态消息
+	if m.statusMessage != "" {
+		statusStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#10b981")).
+			MarginTop(1)
+		s.WriteString(statusStyle.Render(m.statusMessage) + "\n")
+	}
+
+	// 帮助信息
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		MarginTop(1)
+	s.WriteString("\n" + help.Render("• ↑/↓ select cluster • Enter to connect • Esc to main"))
+
+	// 模式指示器
+	modeIndicator := "---Connect---"
+	modeStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7D56F4")).
+		MarginTop(1)
+	s.WriteString("\n" + modeStyle.Render(modeIndicator))
+
+	return s.String()
+}
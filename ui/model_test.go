@@ -0,0 +1,463 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/baixiaoshi/tikvtool/dao"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// driver 按脚本向界面模型发送按键，并同步执行返回的命令，使界面在每次按键后都处于稳定状态
+type driver struct {
+	t    *testing.T
+	m    tea.Model
+	kv   *dao.MemKv
+	quit bool
+}
+
+func newDriver(t *testing.T, kv *dao.MemKv) *driver {
+	t.Helper()
+	return newDriverWithStore(t, kv, kv)
+}
+
+// newDriverWithStore 界面使用 store 访问数据，kv 为其底层存储，用于检查写入结果
+func newDriverWithStore(t *testing.T, kv *dao.MemKv, store dao.KV) *driver {
+	t.Helper()
+	return newDriverWithCluster(t, kv, &Cluster{Name: "test", ApiVersion: "V2", KV: store})
+}
+
+func newDriverWithCluster(t *testing.T, kv *dao.MemKv, cluster *Cluster) *driver {
+	t.Helper()
+	m := InitialModel(context.Background(), cluster.KV, WithCluster(cluster))
+	return &driver{t: t, m: m, kv: kv}
+}
+
+// press 依次发送按键。enter、esc、up、down 等名称对应特殊键，其余字符串按字符逐个输入
+func (d *driver) press(keys ...string) {
+	d.t.Helper()
+	for _, key := range keys {
+		if msg, ok := namedKeys[key]; ok {
+			d.send(msg)
+			continue
+		}
+		for _, r := range key {
+			if r == ' ' {
+				d.send(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+				continue
+			}
+			d.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+}
+
+// repeat 连续发送 n 次同一个按键
+func (d *driver) repeat(key string, n int) {
+	d.t.Helper()
+	for i := 0; i < n; i++ {
+		d.press(key)
+	}
+}
+
+var namedKeys = map[string]tea.KeyMsg{
+	"enter":     {Type: tea.KeyEnter},
+	"esc":       {Type: tea.KeyEsc},
+	"up":        {Type: tea.KeyUp},
+	"down":      {Type: tea.KeyDown},
+	"tab":       {Type: tea.KeyTab},
+	"backspace": {Type: tea.KeyBackspace},
+	"ctrl+s":    {Type: tea.KeyCtrlS},
+	"ctrl+t":    {Type: tea.KeyCtrlT},
+}
+
+func (d *driver) send(msg tea.Msg) {
+	d.t.Helper()
+	if d.quit {
+		d.t.Fatalf("message %v sent after the program quit", msg)
+	}
+	var cmd tea.Cmd
+	d.m, cmd = d.m.Update(msg)
+	d.run(cmd)
+}
+
+// run 同步执行命令并把结果交给模型处理，直到没有后续命令
+func (d *driver) run(cmd tea.Cmd) {
+	d.t.Helper()
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.QuitMsg:
+		d.quit = true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			d.run(c)
+		}
+	default:
+		d.send(msg)
+	}
+}
+
+// ansiPattern 匹配终端控制序列，golden 文件只比较文本布局，不受终端颜色支持的影响
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+// view 返回去掉颜色和行尾空格后的界面内容
+func (d *driver) view() string {
+	lines := strings.Split(ansiPattern.ReplaceAllString(d.m.View(), ""), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// golden 将当前界面与 testdata/<name>.golden 比较，使用 -update 重新生成
+func (d *driver) golden(name string) {
+	d.t.Helper()
+	got := d.view()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			d.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			d.t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		d.t.Fatalf("%v (run go test ./ui -update to create it)", err)
+	}
+	if got != string(want) {
+		d.t.Errorf("view does not match %s\n--- got ---\n%s--- want ---\n%s", path, got, want)
+	}
+}
+
+// selected 返回当前选中的搜索结果，golden 文件去掉了颜色，选中状态单独检查
+func (d *driver) selected() string {
+	d.t.Helper()
+	m := d.m.(model)
+	if m.selectedItem >= len(m.results) {
+		d.t.Fatalf("selected item %d out of %d results", m.selectedItem, len(m.results))
+	}
+	return string(m.results[m.selectedItem].Key)
+}
+
+// value 读取存储中的值，key 不存在时返回 nil
+func (d *driver) value(key string) []byte {
+	d.t.Helper()
+	v, err := d.kv.Get(context.Background(), []byte(key))
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	return v
+}
+
+func newStore(t *testing.T, pairs ...string) *dao.MemKv {
+	t.Helper()
+	kv := dao.NewMemKv()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if err := kv.Put(context.Background(), []byte(pairs[i]), []byte(pairs[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return kv
+}
+
+func TestMainView(t *testing.T) {
+	d := newDriver(t, newStore(t))
+	d.golden("main")
+
+	// 输入时按前缀过滤命令
+	d.press("/a")
+	d.golden("main_filtered")
+}
+
+func TestSearchScrolling(t *testing.T) {
+	kv := newStore(t, "other", "x")
+	for i := 0; i < 60; i++ {
+		if err := kv.Put(context.Background(), []byte(fmt.Sprintf("user/%03d", i)), []byte(fmt.Sprintf("value %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := newDriver(t, kv)
+	d.press("enter", "user/")
+	d.golden("search_results")
+
+	// 选中项移出可见区域后列表随之滚动
+	d.repeat("down", 12)
+	if got := d.selected(); got != "user/012" {
+		t.Fatalf("selected %q after 12 downs, want user/012", got)
+	}
+	d.golden("search_scrolled")
+
+	// 到达已加载的最后一项时自动加载下一页
+	d.repeat("down", 37)
+	d.golden("search_load_more")
+	if got := d.selected(); got != "user/049" {
+		t.Fatalf("selected %q at the end of the first page, want user/049", got)
+	}
+	d.repeat("down", 10)
+	if got := d.selected(); got != "user/059" {
+		t.Fatalf("selected %q at the end of the results, want user/059", got)
+	}
+	d.golden("search_end")
+}
+
+func TestDeleteSelectedKey(t *testing.T) {
+	d := newDriver(t, newStore(t, "user/1", "a", "user/2", "b", "user/3", "c"))
+	d.press("enter", "user/", "down", "dd")
+
+	if v := d.value("user/2"); v != nil {
+		t.Fatalf("user/2 = %q after dd, want deleted", v)
+	}
+	if v := d.value("user/1"); string(v) != "a" {
+		t.Fatalf("user/1 = %q, want it untouched", v)
+	}
+	d.golden("search_dd")
+}
+
+func TestDeleteFromDetail(t *testing.T) {
+	d := newDriver(t, newStore(t, "user/1", `{"name":"alice","roles":["admin"]}`, "user/2", "b"))
+	d.press("enter", "user/", "enter")
+	d.golden("detail")
+
+	d.press("dd")
+	if v := d.value("user/1"); v != nil {
+		t.Fatalf("user/1 = %q after dd, want deleted", v)
+	}
+	d.golden("detail_dd")
+}
+
+func TestEditSave(t *testing.T) {
+	d := newDriver(t, newStore(t, "notes/1", "first line\nsecond line"))
+	d.press("enter", "notes/", "enter", "i")
+	d.golden("edit")
+
+	// 在第一行末尾追加内容，回到命令模式后用 :wq 保存并返回详情
+	d.press("A", " changed", "esc", ":wq")
+	d.golden("edit_command_line")
+	d.press("enter")
+
+	if v := d.value("notes/1"); string(v) != "first line changed\nsecond line" {
+		t.Fatalf("notes/1 = %q after :wq", v)
+	}
+	d.golden("edit_saved")
+}
+
+func TestEditQuitWithoutSaving(t *testing.T) {
+	d := newDriver(t, newStore(t, "notes/1", "text"))
+	d.press("enter", "notes/", "enter", "i", "A", "!!!", "esc", ":q!", "enter")
+
+	if v := d.value("notes/1"); string(v) != "text" {
+		t.Fatalf("notes/1 = %q after :q!, want unchanged", v)
+	}
+}
+
+func TestAddKey(t *testing.T) {
+	d := newDriver(t, newStore(t, "user/1", "a"))
+	d.press("down", "enter", "user/2")
+	d.golden("add_key")
+
+	d.press("enter", `{"name":"bob"}`)
+	d.golden("add_value")
+
+	d.press("ctrl+s")
+	if v := d.value("user/2"); string(v) != `{"name":"bob"}` {
+		t.Fatalf("user/2 = %q after adding", v)
+	}
+	d.golden("add_saved")
+}
+
+func TestReadOnly(t *testing.T) {
+	kv := newStore(t, "user/1", "a", "user/2", "b")
+	d := newDriverWithStore(t, kv, dao.NewReadOnlyKv(kv))
+	d.golden("readonly_main")
+
+	// dd 和 i 只提示只读，不删除也不进入编辑
+	d.press("enter", "user/", "dd")
+	if v := d.value("user/1"); string(v) != "a" {
+		t.Fatalf("user/1 = %q after dd in read-only mode", v)
+	}
+	d.golden("readonly_search")
+
+	d.press("enter", "i")
+	if mode := d.m.(model).mode; mode != modeDetail {
+		t.Fatalf("mode = %v after i in read-only mode, want the detail view", mode)
+	}
+	d.golden("readonly_detail")
+}
+
+func TestStaleSearchResultAfterKeyspaceSwitch(t *testing.T) {
+	d := newDriver(t, newStore(t, "user/1", "a"))
+	d.press("enter", "user/")
+	// 在默认 keyspace 中发出、切换之后才返回的搜索结果
+	m := d.m.(model)
+	stale := m.searchCmd()()
+
+	d.send(keyspaceSwitchMsg{cluster: "test", keyspace: "orders", kvClient: newStore(t, "user/9", "b")})
+	d.press("enter", "user/")
+	if got := d.selected(); got != "user/9" {
+		t.Fatalf("selected %q after switching keyspace, want user/9", got)
+	}
+
+	// 搜索输入相同，但结果来自之前的 keyspace，应当丢弃
+	d.send(stale)
+	if m := d.m.(model); len(m.results) != 1 || string(m.results[0].Key) != "user/9" {
+		t.Fatalf("results = %q after a stale search result", m.results)
+	}
+}
+
+func TestStaleLoadMoreAfterDelete(t *testing.T) {
+	kv := newStore(t)
+	for i := 0; i < 60; i++ {
+		if err := kv.Put(context.Background(), []byte(fmt.Sprintf("user/%03d", i)), []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := newDriver(t, kv)
+	d.press("enter", "user/")
+	// 下一页还在加载时删除了一个key，列表重新从第一页开始
+	pending := d.m.(model).loadMoreCmd()
+	d.press("dd")
+	if m := d.m.(model); len(m.results) != 50 || string(m.results[0].Key) != "user/001" {
+		t.Fatalf("results after dd = %d keys starting at %q", len(m.results), m.results[0].Key)
+	}
+
+	next := d.m.(model).nextKey
+
+	d.send(pending())
+	m := d.m.(model)
+	if len(m.results) != 50 {
+		t.Fatalf("%d results after a stale page arrived, want 50", len(m.results))
+	}
+	if string(m.nextKey) != string(next) {
+		t.Fatalf("next page starts at %q after a stale page, want %q", m.nextKey, next)
+	}
+}
+
+// failingGetKv 读取 value 总是失败，用于检查读取失败后不能编辑
+type failingGetKv struct {
+	dao.KV
+}
+
+func (f failingGetKv) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return nil, errors.New("region unavailable")
+}
+
+func TestDetailLoadFailed(t *testing.T) {
+	kv := newStore(t, "user/1", "a")
+	d := newDriverWithStore(t, kv, failingGetKv{kv})
+	d.press("enter", "user/", "enter")
+	d.golden("detail_load_failed")
+
+	// 读取失败时不能进入编辑，否则保存会用空内容覆盖原来的 value
+	d.press("i", ":w", "enter")
+	if mode := d.m.(model).mode; mode != modeDetail {
+		t.Fatalf("mode = %v after i on a failed load, want the detail view", mode)
+	}
+	if v := d.value("user/1"); string(v) != "a" {
+		t.Fatalf("user/1 = %q, want it untouched", v)
+	}
+	if got := d.m.(model).statusMessage; got != "Cannot edit: failed to load value: region unavailable" {
+		t.Fatalf("status = %q", got)
+	}
+}
+
+func TestDetailKeyNotFound(t *testing.T) {
+	kv := newStore(t, "user/1", "a")
+	d := newDriver(t, kv)
+	d.press("enter", "user/")
+	// 搜索之后key被其他客户端删除
+	if err := kv.Delete(context.Background(), []byte("user/1")); err != nil {
+		t.Fatal(err)
+	}
+	d.press("enter", "i", "ctrl+s")
+	if mode := d.m.(model).mode; mode != modeDetail {
+		t.Fatalf("mode = %v after i on a missing key, want the detail view", mode)
+	}
+	if v := d.value("user/1"); v != nil {
+		t.Fatalf("user/1 = %q, want it to stay deleted", v)
+	}
+	if got := d.m.(model).statusMessage; got != "Cannot edit: key not found" {
+		t.Fatalf("status = %q", got)
+	}
+}
+
+func newProtectedDriver(t *testing.T, kv *dao.MemKv) *driver {
+	t.Helper()
+	return newDriverWithCluster(t, kv, &Cluster{
+		Name:       "test",
+		ApiVersion: "V2",
+		KV:         kv,
+		Protected: []ProtectedPrefix{
+			{Prefix: []byte("user/"), Policy: ProtectConfirm},
+			{Prefix: []byte("config/"), Policy: ProtectTypeKey},
+			{Prefix: []byte("config/tmp/"), Policy: ProtectDeny},
+		},
+	})
+}
+
+func TestProtectedConfirm(t *testing.T) {
+	d := newProtectedDriver(t, newStore(t, "user/1", "a", "user/2", "b"))
+	d.press("enter", "user/", "dd")
+	d.golden("protect_confirm")
+
+	// n 取消，key 保留
+	d.press("n")
+	if v := d.value("user/1"); string(v) != "a" {
+		t.Fatalf("user/1 = %q after cancelling, want it kept", v)
+	}
+	d.golden("protect_cancelled")
+
+	d.press("dd", "y")
+	if v := d.value("user/1"); v != nil {
+		t.Fatalf("user/1 = %q after confirming, want deleted", v)
+	}
+}
+
+func TestProtectedTypeKey(t *testing.T) {
+	d := newProtectedDriver(t, newStore(t, "config/app", "port: 80"))
+	d.press("enter", "config/", "enter", "i", "A", "80", "esc", ":wq", "enter")
+
+	// y 对 type-key 策略无效，输入错误的key名也不会保存
+	d.press("y", "config/ap", "enter")
+	if v := d.value("config/app"); string(v) != "port: 80" {
+		t.Fatalf("config/app = %q before typing the key name", v)
+	}
+	d.golden("protect_type_key_mismatch")
+
+	d.press("config/app", "enter")
+	if v := d.value("config/app"); string(v) != "port: 8080" {
+		t.Fatalf("config/app = %q after typing the key name", v)
+	}
+	d.golden("protect_type_key_saved")
+}
+
+func TestProtectedDeny(t *testing.T) {
+	d := newProtectedDriver(t, newStore(t))
+
+	// config/tmp/ 比 config/ 更具体，使用 deny 策略
+	d.press("down", "enter", "config/tmp/x", "enter", "v", "ctrl+s")
+	if v := d.value("config/tmp/x"); v != nil {
+		t.Fatalf("config/tmp/x = %q, want the add to be denied", v)
+	}
+	d.golden("protect_deny")
+
+	// 不受保护的key直接写入
+	d.press("esc", "down", "enter", "other", "enter", "v", "ctrl+s")
+	if v := d.value("other"); string(v) != "v" {
+		t.Fatalf("other = %q, want it added without confirmation", v)
+	}
+}
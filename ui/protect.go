@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ProtectPolicy 修改受保护key时使用的策略
+type ProtectPolicy int
+
+const (
+	ProtectConfirm ProtectPolicy = iota // 弹出确认框，按 y 后执行
+	ProtectTypeKey                      // 需要输入完整的key名才能执行
+	ProtectDeny                         // 禁止删除和写入
+)
+
+func (p ProtectPolicy) String() string {
+	switch p {
+	case ProtectConfirm:
+		return "confirm"
+	case ProtectTypeKey:
+		return "type-key"
+	case ProtectDeny:
+		return "deny"
+	default:
+		return fmt.Sprintf("ProtectPolicy(%d)", int(p))
+	}
+}
+
+// ProtectedPrefix 一个受保护的key前缀，在界面中删除、保存或添加匹配的key时按 Policy 处理
+type ProtectedPrefix struct {
+	Prefix []byte
+	Policy ProtectPolicy
+}
+
+// matchProtected 返回与 key 匹配的最长受保护前缀，多条规则重叠时以更具体的为准
+func matchProtected(rules []ProtectedPrefix, key []byte) (ProtectedPrefix, bool) {
+	var match ProtectedPrefix
+	found := false
+	for _, rule := range rules {
+		if !bytes.HasPrefix(key, rule.Prefix) {
+			continue
+		}
+		if !found || len(rule.Prefix) > len(match.Prefix) {
+			match = rule
+			found = true
+		}
+	}
+	return match, found
+}
+
+// pendingWrite 等待在确认框中确认的修改
+type pendingWrite struct {
+	action   string // delete、save 或 add
+	key      []byte
+	rule     ProtectedPrefix
+	cmd      tea.Cmd // 确认后执行的命令
+	input    string  // type-key 策略下已输入的key名
+	mismatch bool    // 输入的key名与要修改的key不一致
+}
+
+// guardWrite 在执行修改 key 的命令前检查受保护前缀：未受保护时直接执行，deny 时拒绝，
+// 其余策略弹出确认框，确认后才执行 cmd
+func (m model) guardWrite(action string, key []byte, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	if cmd == nil {
+		return m, nil
+	}
+	rule, ok := matchProtected(m.protected, key)
+	if !ok {
+		return m, cmd
+	}
+	if rule.Policy == ProtectDeny {
+		m.statusMessage = fmt.Sprintf("Key '%s' is protected by prefix '%s': %s denied",
+			m.displayKey(key), m.displayKey(rule.Prefix), action)
+		return m, nil
+	}
+
+	m.confirm = &pendingWrite{action: action, key: key, rule: rule, cmd: cmd}
+	return m, nil
+}
+
+// updateConfirm 处理确认框的按键，确认框打开时其他按键不会传给当前视图
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.confirm
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		return m.cancelConfirm()
+	}
+
+	if p.rule.Policy == ProtectConfirm {
+		switch msg.String() {
+		case "y", "Y":
+			m.confirm = nil
+			return m, p.cmd
+		case "n", "N":
+			return m.cancelConfirm()
+		}
+		return m, nil
+	}
+
+	// 输入完整的key名确认
+	switch msg.Type {
+	case tea.KeyEnter:
+		if p.input == m.displayKey(p.key) || p.input == string(p.key) {
+			m.confirm = nil
+			return m, p.cmd
+		}
+		p.mismatch = true
+		p.input = ""
+	case tea.KeyBackspace:
+		if len(p.input) > 0 {
+			runes := []rune(p.input)
+			p.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.input += msg.String()
+		p.mismatch = false
+	}
+	return m, nil
+}
+
+// cancelConfirm 关闭确认框，不执行修改
+func (m model) cancelConfirm() (tea.Model, tea.Cmd) {
+	p := m.confirm
+	m.confirm = nil
+	m.statusMessage = fmt.Sprintf("Cancelled %s of protected key '%s'", p.action, m.displayKey(p.key))
+	return m, nil
+}
+
+// viewConfirm 渲染确认框
+func (m model) viewConfirm() string {
+	p := m.confirm
+	var s strings.Builder
+
+	s.WriteString(m.renderTitle("⚠️  Protected Key") + "\n")
+
+	warnStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#ef4444"))
+	keyStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#fbbf24"))
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6b7280"))
+
+	var body strings.Builder
+	body.WriteString(warnStyle.Render(fmt.Sprintf("%s protected key?", strings.ToUpper(p.action[:1])+p.action[1:])) + "\n\n")
+	body.WriteString("  " + keyStyle.Render(m.displayKey(p.key)) + "\n\n")
+	body.WriteString(hintStyle.Render(fmt.Sprintf("Matches protected prefix '%s' (%s)", m.displayKey(p.rule.Prefix), p.rule.Policy)) + "\n\n")
+
+	if p.rule.Policy == ProtectTypeKey {
+		body.WriteString("Type the key name to confirm:\n")
+		body.WriteString("> " + p.input + "|\n")
+		if p.mismatch {
+			body.WriteString(warnStyle.Render("Key name does not match") + "\n")
+		}
+		body.WriteString("\n" + hintStyle.Render("• Enter to confirm • Esc to cancel"))
+	} else {
+		body.WriteString(hintStyle.Render("• y to confirm • n/Esc to cancel"))
+	}
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(lipgloss.Color("#ef4444")).
+		Padding(1, 2).
+		Width(80)
+	s.WriteString(dialogStyle.Render(body.String()))
+
+	return s.String()
+}
@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Encoding int
+
+const (
+	EncodingAuto    Encoding = iota // 可读文本原样显示，二进制数据转义显示
+	EncodingEscaped                 // 不可打印字节显示为 \xNN
+	EncodingHex
+	EncodingBase64
+	EncodingUTF8 // 原样显示，仅作为 EncodingAuto 解析后的结果
+)
+
+// GetEncodingName 获取显示编码名称
+func GetEncodingName(enc Encoding) string {
+	switch enc {
+	case EncodingEscaped:
+		return "escaped"
+	case EncodingHex:
+		return "hex"
+	case EncodingBase64:
+		return "base64"
+	case EncodingUTF8:
+		return "utf8"
+	default:
+		return "auto"
+	}
+}
+
+// NextEncoding 切换到下一种显示编码：auto -> escaped -> hex -> base64 -> auto
+func NextEncoding(enc Encoding) Encoding {
+	switch enc {
+	case EncodingAuto:
+		return EncodingEscaped
+	case EncodingEscaped:
+		return EncodingHex
+	case EncodingHex:
+		return EncodingBase64
+	default:
+		return EncodingAuto
+	}
+}
+
+// IsText 判断数据是否为可以直接显示的文本：合法的 UTF-8 且不含换行、制表符以外的控制字符
+func IsText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if r == '\n' || r == '\r' || r == '\t' {
+			continue
+		}
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveEncoding 解析实际使用的编码，EncodingAuto 根据数据内容选择 EncodingUTF8 或 EncodingEscaped
+func ResolveEncoding(data []byte, enc Encoding) Encoding {
+	if enc != EncodingAuto {
+		return enc
+	}
+	if IsText(data) {
+		return EncodingUTF8
+	}
+	return EncodingEscaped
+}
+
+// DisplayKey 将key渲染为单行文本，换行等控制字符总是被转义
+func DisplayKey(key []byte, enc Encoding) string {
+	enc = ResolveEncoding(key, enc)
+	if enc == EncodingUTF8 && strings.ContainsAny(string(key), "\n\r\t") {
+		enc = EncodingEscaped
+	}
+	return encode(key, enc, false)
+}
+
+// DisplayValue 将value渲染为文本，返回渲染结果和实际使用的编码。
+// 转义编码下保留真实的换行，hex 和 base64 按固定宽度换行
+func DisplayValue(value []byte, enc Encoding) (string, Encoding) {
+	enc = ResolveEncoding(value, enc)
+	return encode(value, enc, true), enc
+}
+
+func encode(data []byte, enc Encoding, multiline bool) string {
+	switch enc {
+	case EncodingEscaped:
+		return Escape(data, multiline)
+	case EncodingHex:
+		s := hex.EncodeToString(data)
+		if multiline {
+			return wrap(s, 64)
+		}
+		return "0x" + s
+	case EncodingBase64:
+		s := base64.StdEncoding.EncodeToString(data)
+		if multiline {
+			return wrap(s, 76)
+		}
+		return s
+	default:
+		return string(data)
+	}
+}
+
+// DecodeDisplay 将按 enc 渲染后（可能经过编辑）的文本还原为原始字节
+func DecodeDisplay(text string, enc Encoding) ([]byte, error) {
+	switch enc {
+	case EncodingEscaped:
+		return Unescape(text)
+	case EncodingHex:
+		s := strings.TrimPrefix(strings.Join(strings.Fields(text), ""), "0x")
+		return hex.DecodeString(s)
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	default:
+		return []byte(text), nil
+	}
+}
+
+// Escape 转义不可打印的字节：反斜杠显示为 \\，非法 UTF-8 和控制字符显示为 \xNN，
+// keepNewlines 为 true 时保留真实的换行
+func Escape(data []byte, keepNewlines bool) string {
+	var s strings.Builder
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			fmt.Fprintf(&s, "\\x%02x", data[0])
+		case r == '\\':
+			s.WriteString(`\\`)
+		case r == '\n' && keepNewlines:
+			s.WriteByte('\n')
+		case r == '\n':
+			s.WriteString(`\n`)
+		case r == '\r':
+			s.WriteString(`\r`)
+		case r == '\t':
+			s.WriteString(`\t`)
+		case unicode.IsPrint(r):
+			s.Write(data[:size])
+		default:
+			for _, b := range data[:size] {
+				fmt.Fprintf(&s, "\\x%02x", b)
+			}
+		}
+		data = data[size:]
+	}
+	return s.String()
+}
+
+// Unescape 还原 Escape 的结果，支持 \xNN、\n、\r、\t、\0 和 \\
+func Unescape(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return nil, fmt.Errorf("trailing backslash at offset %d", i)
+		}
+		i++
+		switch s[i] {
+		case '\\':
+			out = append(out, '\\')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case '0':
+			out = append(out, 0)
+		case 'x':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("incomplete \\x escape at offset %d", i-1)
+			}
+			b, err := hex.DecodeString(s[i+1 : i+3])
+			if err != nil {
+				return nil, fmt.Errorf("invalid \\x escape at offset %d", i-1)
+			}
+			out = append(out, b[0])
+			i += 2
+		default:
+			return nil, fmt.Errorf("unknown escape \\%c at offset %d", s[i], i-1)
+		}
+	}
+	return out, nil
+}
+
+// ParseKeyInput 解析用户输入的key或前缀：0x 开头按十六进制解析，
+// 包含反斜杠时按转义序列解析，否则使用原始文本
+func ParseKeyInput(input string) ([]byte, error) {
+	if strings.HasPrefix(input, "0x") || strings.HasPrefix(input, "0X") {
+		b, err := hex.DecodeString(input[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex key %q", input)
+		}
+		return b, nil
+	}
+	if strings.Contains(input, `\`) {
+		return Unescape(input)
+	}
+	return []byte(input), nil
+}
+
+// wrap 按固定宽度切分文本
+func wrap(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	var lines []string
+	for len(s) > width {
+		lines = append(lines, s[:width])
+		s = s[width:]
+	}
+	if len(s) > 0 {
+		lines = append(lines, s)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HexDumpWidth 十六进制视图每行显示的字节数
+const HexDumpWidth = 16
+
+// HexDump 以 xxd 风格渲染 data 中从 offset 开始的最多 rows 行，
+// 每行包含偏移、按两字节分组的十六进制和 ASCII 栏
+func HexDump(data []byte, offset, rows int) []string {
+	var lines []string
+	for i := 0; i < rows && offset < len(data); i++ {
+		end := offset + HexDumpWidth
+		if end > len(data) {
+			end = len(data)
+		}
+		lines = append(lines, hexDumpLine(data[offset:end], offset))
+		offset = end
+	}
+	return lines
+}
+
+func hexDumpLine(row []byte, offset int) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%08x: ", offset)
+	for i := 0; i < HexDumpWidth; i++ {
+		if i < len(row) {
+			fmt.Fprintf(&s, "%02x", row[i])
+		} else {
+			s.WriteString("  ")
+		}
+		if i%2 == 1 {
+			s.WriteByte(' ')
+		}
+	}
+	s.WriteByte(' ')
+	for _, b := range row {
+		if b >= 0x20 && b < 0x7f {
+			s.WriteByte(b)
+		} else {
+			s.WriteByte('.')
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// randomBytes 生成固定种子的随机数据，偏向反斜杠、控制字符和多字节 UTF-8 的片段
+func randomBytes(r *rand.Rand, n int) []byte {
+	pieces := [][]byte{{'\\'}, {'\n'}, {'\r'}, {'\t'}, {0}, {0x7f}, {0xff}, {0xc3}, []byte("é"), []byte("中"), []byte("\\x"), []byte("\u2028"), []byte("\ufffd")}
+	var data []byte
+	for len(data) < n {
+		if r.Intn(3) == 0 {
+			data = append(data, pieces[r.Intn(len(pieces))]...)
+		} else {
+			data = append(data, byte(r.Intn(256)))
+		}
+	}
+	return data
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	inputs := [][]byte{nil, {}, []byte(`\`), []byte(`\\x41`), []byte("a\nb"), {0xe4, 0xb8}}
+	for i := 0; i < 2000; i++ {
+		inputs = append(inputs, randomBytes(r, r.Intn(64)))
+	}
+	// 所有单字节
+	for b := 0; b < 256; b++ {
+		inputs = append(inputs, []byte{byte(b)})
+	}
+
+	for _, data := range inputs {
+		for _, keepNewlines := range []bool{false, true} {
+			escaped := Escape(data, keepNewlines)
+			if !keepNewlines && strings.ContainsAny(escaped, "\n\r\t") {
+				t.Fatalf("Escape(%q, false) = %q contains a raw control character", data, escaped)
+			}
+			got, err := Unescape(escaped)
+			if err != nil {
+				t.Fatalf("Unescape(Escape(%q, %v)) = %v", data, keepNewlines, err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("Unescape(Escape(%q, %v)) = %q", data, keepNewlines, got)
+			}
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{[]byte("plain text"), "plain text"},
+		{[]byte(`a\b`), `a\\b`},
+		{[]byte("a\nb\tc\r"), `a\nb\tc\r`},
+		{[]byte{0x00, 0x01, 0x7f}, `\x00\x01\x7f`},
+		{[]byte{'k', 0xff, 0xfe}, `k\xff\xfe`},
+		{[]byte("中文"), "中文"},
+		{[]byte{0xe4, 0xb8}, `\xe4\xb8`},   // 被截断的多字节字符
+		{[]byte("\u200b"), `\xe2\x80\x8b`}, // 不可打印的格式字符按字节转义
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.data, false); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+	if got := Escape([]byte("a\nb"), true); got != "a\nb" {
+		t.Errorf("Escape with keepNewlines = %q", got)
+	}
+}
+
+func TestUnescapeInvalid(t *testing.T) {
+	tests := []string{
+		`\`,      // 结尾的反斜杠
+		`abc\`,   // 结尾的反斜杠
+		`\x`,     // 缺少两位十六进制
+		`\x4`,    // 只有一位
+		`a\x4`,   // 只有一位
+		`\xg1`,   // 非十六进制字符
+		`\x1z`,   // 非十六进制字符
+		`\x-1`,   // 非十六进制字符
+		`\q`,     // 未知的转义
+		`\u0041`, // 不支持 \u
+	}
+	for _, s := range tests {
+		if got, err := Unescape(s); err == nil {
+			t.Errorf("Unescape(%q) = %q, want an error", s, got)
+		}
+	}
+
+	valid := map[string][]byte{
+		`\x41\x4a`: []byte("AJ"),
+		`\xFF`:     {0xff},
+		`\0\\\t`:   {0, '\\', '\t'},
+		`x\x41x`:   []byte("xAx"),
+	}
+	for s, want := range valid {
+		if got, err := Unescape(s); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Unescape(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+}
+
+func TestParseKeyInput(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []byte
+		wantErr bool
+	}{
+		{input: "user/1", want: []byte("user/1")},
+		{input: "", want: []byte{}},
+		{input: "0x00ff", want: []byte{0x00, 0xff}},
+		{input: "0XAB", want: []byte{0xab}},
+		{input: "0x", want: []byte{}},
+		{input: "0x0", wantErr: true},
+		{input: "0xzz", wantErr: true},
+		{input: `user\x00id`, want: []byte("user\x00id")},
+		{input: `bad\x0`, wantErr: true},
+		{input: "0a1b", want: []byte("0a1b")}, // 没有 0x 前缀时按原始文本处理
+		{input: "中文", want: []byte("中文")},
+	}
+	for _, tt := range tests {
+		got, err := ParseKeyInput(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseKeyInput(%q) = %q, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("ParseKeyInput(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeDisplayRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	inputs := [][]byte{{}, []byte("text"), bytes.Repeat([]byte{0xab}, 200)}
+	for i := 0; i < 200; i++ {
+		inputs = append(inputs, randomBytes(r, r.Intn(300)))
+	}
+	for _, data := range inputs {
+		for _, enc := range []Encoding{EncodingEscaped, EncodingHex, EncodingBase64} {
+			text, resolved := DisplayValue(data, enc)
+			got, err := DecodeDisplay(text, resolved)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("%s: DecodeDisplay(DisplayValue(%q)) = %q, %v", GetEncodingName(enc), data, got, err)
+			}
+		}
+		// key 的单行显示
+		for _, enc := range []Encoding{EncodingEscaped, EncodingHex, EncodingBase64} {
+			got, err := DecodeDisplay(DisplayKey(data, enc), enc)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("%s: DecodeDisplay(DisplayKey(%q)) = %q, %v", GetEncodingName(enc), data, got, err)
+			}
+		}
+	}
+}
+
+func TestDecodeDisplay(t *testing.T) {
+	tests := []struct {
+		text    string
+		enc     Encoding
+		want    []byte
+		wantErr bool
+	}{
+		{text: "0x00ff", enc: EncodingHex, want: []byte{0x00, 0xff}},
+		{text: "00 ff\n0a", enc: EncodingHex, want: []byte{0x00, 0xff, 0x0a}}, // 编辑时加入的空白被忽略
+		{text: "0f0", enc: EncodingHex, wantErr: true},
+		{text: "xyz", enc: EncodingHex, wantErr: true},
+		{text: "AP8=", enc: EncodingBase64, want: []byte{0x00, 0xff}},
+		{text: "AP8=\n", enc: EncodingBase64, want: []byte{0x00, 0xff}},
+		{text: "AP8", enc: EncodingBase64, wantErr: true},
+		{text: "!!!!", enc: EncodingBase64, wantErr: true},
+		{text: `a\x00`, enc: EncodingEscaped, want: []byte{'a', 0}},
+		{text: `a\x0`, enc: EncodingEscaped, wantErr: true},
+		{text: `a\x00`, enc: EncodingUTF8, want: []byte(`a\x00`)},
+	}
+	for _, tt := range tests {
+		got, err := DecodeDisplay(tt.text, tt.enc)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("DecodeDisplay(%q, %s) = %q, want an error", tt.text, GetEncodingName(tt.enc), got)
+			}
+			continue
+		}
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("DecodeDisplay(%q, %s) = %q, %v, want %q", tt.text, GetEncodingName(tt.enc), got, err, tt.want)
+		}
+	}
+}
+
+func TestIsText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", []byte{}, true},
+		{"ascii", []byte("hello world"), true},
+		{"whitespace", []byte("a\nb\r\nc\td"), true},
+		{"unicode", []byte("中文 émoji 🎉"), true},
+		{"nul byte", []byte("a\x00b"), false},
+		{"escape character", []byte("\x1b[31mred"), false},
+		{"delete character", []byte{0x7f}, false},
+		{"c1 control", []byte("\u0085"), true}, // NEL 属于空白字符
+		{"other c1 control", []byte("\u0080"), false},
+		{"zero width space", []byte("\u200b"), false},
+		{"invalid utf8", []byte{0xff, 0xfe}, false},
+		{"truncated utf8", []byte{'a', 0xe4, 0xb8}, false},
+		{"overlong encoding", []byte{0xc0, 0xaf}, false},
+		{"surrogate half", []byte{0xed, 0xa0, 0x80}, false},
+		{"replacement character", []byte("\ufffd"), true},
+	}
+	for _, tt := range tests {
+		if got := IsText(tt.data); got != tt.want {
+			t.Errorf("%s: IsText(%q) = %v, want %v", tt.name, tt.data, got, tt.want)
+		}
+	}
+
+	if got := ResolveEncoding([]byte("text"), EncodingAuto); got != EncodingUTF8 {
+		t.Errorf("ResolveEncoding(text) = %s", GetEncodingName(got))
+	}
+	if got := ResolveEncoding([]byte{0xff}, EncodingAuto); got != EncodingEscaped {
+		t.Errorf("ResolveEncoding(binary) = %s", GetEncodingName(got))
+	}
+	// 可读文本中的换行在单行显示的key中也要转义
+	if got := DisplayKey([]byte("a\nb"), EncodingAuto); got != `a\nb` {
+		t.Errorf("DisplayKey(a\\nb) = %q", got)
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	data := []byte("0123456789abcdef\x00\x01ABC\xff")
+	got := HexDump(data, 0, 10)
+	want := []string{
+		"00000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef",
+		"00000010: 0001 4142 43ff                           ..ABC.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("HexDump =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// 从中间的偏移开始，只显示 rows 行
+	long := bytes.Repeat([]byte{'x'}, 100)
+	got = HexDump(long, 32, 2)
+	if len(got) != 2 || !strings.HasPrefix(got[0], "00000020: ") || !strings.HasPrefix(got[1], "00000030: ") {
+		t.Fatalf("HexDump(offset 32, 2 rows) = %q", got)
+	}
+	if got := HexDump(long, 100, 5); len(got) != 0 {
+		t.Fatalf("HexDump past the end = %q", got)
+	}
+	if got := HexDump(nil, 0, 5); len(got) != 0 {
+		t.Fatalf("HexDump(nil) = %q", got)
+	}
+}
@@ -20,26 +20,26 @@ const (
 // DetectFormat 自动检测文本格式
 func DetectFormat(content string) Format {
 	content = strings.TrimSpace(content)
-	
+
 	if content == "" {
 		return FormatPlainText
 	}
-	
+
 	// 检测 JSON
 	if isValidJSON(content) {
 		return FormatJSON
 	}
-	
+
 	// 检测 YAML
 	if isValidYAML(content) {
 		return FormatYAML
 	}
-	
+
 	// 检测 TOML
 	if isValidTOML(content) {
 		return FormatTOML
 	}
-	
+
 	return FormatPlainText
 }
 
@@ -47,7 +47,7 @@ func DetectFormat(content string) Format {
 func FormatContent(content string) (string, Format) {
 	format := DetectFormat(content)
 	formatted := content
-	
+
 	switch format {
 	case FormatJSON:
 		if f, err := formatJSON(content); err == nil {
@@ -62,7 +62,7 @@ func FormatContent(content string) (string, Format) {
 			formatted = f
 		}
 	}
-	
+
 	return formatted, format
 }
 
@@ -106,12 +106,12 @@ func formatJSON(content string) (string, error) {
 	if err := json.Unmarshal([]byte(content), &obj); err != nil {
 		return content, err
 	}
-	
+
 	formatted, err := json.MarshalIndent(obj, "", "  ")
 	if err != nil {
 		return content, err
 	}
-	
+
 	return string(formatted), nil
 }
 
@@ -121,12 +121,12 @@ func formatYAML(content string) (string, error) {
 	if err := yaml.Unmarshal([]byte(content), &obj); err != nil {
 		return content, err
 	}
-	
+
 	formatted, err := yaml.Marshal(obj)
 	if err != nil {
 		return content, err
 	}
-	
+
 	return strings.TrimSpace(string(formatted)), nil
 }
 
@@ -136,12 +136,50 @@ func formatTOML(content string) (string, error) {
 	if _, err := toml.Decode(content, &obj); err != nil {
 		return content, err
 	}
-	
+
 	var buf strings.Builder
 	if err := toml.NewEncoder(&buf).Encode(obj); err != nil {
 		return content, err
 	}
-	
+
 	return strings.TrimSpace(buf.String()), nil
 }
 
+// DetectStructuredFormat 检测内容是否为结构化数据：JSON 和 YAML 的顶层必须是对象或数组，
+// TOML 至少包含一个键。纯文本和单个标量（如 hello-world、42）返回 FormatPlainText
+func DetectStructuredFormat(content string) Format {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return FormatPlainText
+	}
+
+	var js interface{}
+	if json.Unmarshal([]byte(content), &js) == nil {
+		if isCollection(js) {
+			return FormatJSON
+		}
+		return FormatPlainText
+	}
+
+	// TOML 先于 YAML 检测，yaml.v3 会把 [server] 这样的表头当作数组并忽略后面的内容
+	var tml map[string]interface{}
+	if _, err := toml.Decode(content, &tml); err == nil && len(tml) > 0 {
+		return FormatTOML
+	}
+
+	var yml interface{}
+	if yaml.Unmarshal([]byte(content), &yml) == nil && isCollection(yml) {
+		return FormatYAML
+	}
+
+	return FormatPlainText
+}
+
+// isCollection 判断解析结果是否为对象（映射）或数组
+func isCollection(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestDetectStructuredFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Format
+	}{
+		{"json object", `{"name":"alice","roles":["admin"]}`, FormatJSON},
+		{"json array", `[1, 2, 3]`, FormatJSON},
+		{"json empty object", ` {} `, FormatJSON},
+		{"json string", `"hello"`, FormatPlainText},
+		{"json number", `42`, FormatPlainText},
+		{"json null", `null`, FormatPlainText},
+		{"invalid json object", `{"name":`, FormatPlainText},
+		{"yaml mapping", "name: alice\nage: 30", FormatYAML},
+		{"yaml sequence", "- a\n- b", FormatYAML},
+		{"yaml nested", "server:\n  port: 80\n  hosts:\n    - a", FormatYAML},
+		{"yaml integer keys", "1: one\n2: two", FormatYAML},
+		{"plain word with dash", "hello-world", FormatPlainText},
+		{"plain text with colon", "note: see below: more", FormatPlainText},
+		{"yaml scalar", "just some text", FormatPlainText},
+		{"toml table", "[server]\nport = 80", FormatTOML},
+		{"toml key", `name = "alice"`, FormatTOML},
+		{"toml comment only", "# nothing here", FormatPlainText},
+		{"empty", "  \n ", FormatPlainText},
+	}
+	for _, tt := range tests {
+		if got := DetectStructuredFormat(tt.content); got != tt.want {
+			t.Errorf("%s: DetectStructuredFormat(%q) = %s, want %s", tt.name, tt.content, GetFormatName(got), GetFormatName(tt.want))
+		}
+	}
+}
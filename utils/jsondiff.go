@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// JSONDiff 结构化比较两个 JSON 文档，返回按路径排序的差异描述，
+// 例如 `$.user.age: 30 -> 31`、`$.tags[2]: added "new"`。内容相同（仅格式不同）时返回空
+func JSONDiff(left, right string) ([]string, error) {
+	var l, r interface{}
+	if err := decodeJSON(left, &l); err != nil {
+		return nil, fmt.Errorf("invalid left JSON: %v", err)
+	}
+	if err := decodeJSON(right, &r); err != nil {
+		return nil, fmt.Errorf("invalid right JSON: %v", err)
+	}
+
+	var diffs []string
+	diffJSON("$", l, r, &diffs)
+	return diffs, nil
+}
+
+// decodeJSON 使用 json.Number 保留数字的原始精度
+func decodeJSON(s string, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func diffJSON(path string, l, r interface{}, diffs *[]string) {
+	switch lv := l.(type) {
+	case map[string]interface{}:
+		if rv, ok := r.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(lv)+len(rv))
+			for k := range lv {
+				keys = append(keys, k)
+			}
+			for k := range rv {
+				if _, ok := lv[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				child := path + "." + jsonPathKey(k)
+				lc, lok := lv[k]
+				rc, rok := rv[k]
+				switch {
+				case !rok:
+					*diffs = append(*diffs, fmt.Sprintf("%s: removed %s", child, compactJSON(lc)))
+				case !lok:
+					*diffs = append(*diffs, fmt.Sprintf("%s: added %s", child, compactJSON(rc)))
+				default:
+					diffJSON(child, lc, rc, diffs)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if rv, ok := r.([]interface{}); ok {
+			for i := 0; i < len(lv) || i < len(rv); i++ {
+				child := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(rv):
+					*diffs = append(*diffs, fmt.Sprintf("%s: removed %s", child, compactJSON(lv[i])))
+				case i >= len(lv):
+					*diffs = append(*diffs, fmt.Sprintf("%s: added %s", child, compactJSON(rv[i])))
+				default:
+					diffJSON(child, lv[i], rv[i], diffs)
+				}
+			}
+			return
+		}
+	}
+
+	if ln, ok := l.(json.Number); ok {
+		if rn, ok := r.(json.Number); ok && numbersEqual(ln, rn) {
+			return
+		}
+	}
+	if !reflect.DeepEqual(l, r) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, compactJSON(l), compactJSON(r)))
+	}
+}
+
+// numbersEqual 按数值比较，1 和 1.0、1e2 和 100 视为相等
+func numbersEqual(l, r json.Number) bool {
+	if l == r {
+		return true
+	}
+	lv, lok := new(big.Rat).SetString(string(l))
+	rv, rok := new(big.Rat).SetString(string(r))
+	return lok && rok && lv.Cmp(rv) == 0
+}
+
+// jsonPathKey 对不是合法标识符的字段名加引号
+func jsonPathKey(k string) string {
+	if k == "" {
+		return strconv.Quote(k)
+	}
+	for i, c := range k {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return strconv.Quote(k)
+	}
+	return k
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	const maxLen = 80
+	if len(data) > maxLen {
+		return string(data[:maxLen-3]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right string
+		want        []string
+	}{
+		{"identical", `{"a":1}`, `{"a":1}`, nil},
+		{"formatting and field order", `{"a":1,"b":[1,2]}`, "{\n  \"b\": [1, 2],\n  \"a\": 1\n}", nil},
+		{"changed field", `{"user":{"age":30}}`, `{"user":{"age":31}}`, []string{"$.user.age: 30 -> 31"}},
+		{"added and removed fields", `{"a":1,"b":2}`, `{"b":2,"c":3}`, []string{"$.a: removed 1", "$.c: added 3"}},
+		{"quoted field names", `{"a-b":1,"":2}`, `{"a-b":2,"":3}`, []string{`$."": 2 -> 3`, `$."a-b": 1 -> 2`}},
+		{"type change", `{"a":"1"}`, `{"a":1}`, []string{`$.a: "1" -> 1`}},
+		{"object replaced by array", `{"a":{"x":1}}`, `{"a":[1]}`, []string{`$.a: {"x":1} -> [1]`}},
+
+		// 数字按数值比较，不受写法影响，也不会因 float64 丢失精度
+		{"integer and float", `{"n":1}`, `{"n":1.0}`, nil},
+		{"exponent", `[1e2, 0.5]`, `[100, 5e-1]`, nil},
+		{"negative zero", `[0]`, `[-0.0]`, nil},
+		{"large integers differ", `{"id":9007199254740993}`, `{"id":9007199254740992}`, []string{"$.id: 9007199254740993 -> 9007199254740992"}},
+		{"precise decimals differ", `[0.10000000000000000001]`, `[0.1]`, []string{"$[0]: 0.10000000000000000001 -> 0.1"}},
+		{"number and string", `[1]`, `["1"]`, []string{`$[0]: 1 -> "1"`}},
+
+		// 数组逐个元素比较，嵌套路径带下标
+		{"array element changed", `[1,2,3]`, `[1,5,3]`, []string{"$[1]: 2 -> 5"}},
+		{"array grows", `{"tags":["a"]}`, `{"tags":["a","b","c"]}`, []string{`$.tags[1]: added "b"`, `$.tags[2]: added "c"`}},
+		{"array shrinks", `[[1,2],[3]]`, `[[1]]`, []string{"$[0][1]: removed 2", "$[1]: removed [3]"}},
+		{"nested arrays", `{"m":[[1,[2,3]],{"k":[4]}]}`, `{"m":[[1,[2,4]],{"k":[4,5]}]}`, []string{"$.m[0][1][1]: 3 -> 4", "$.m[1].k[1]: added 5"}},
+		{"top level scalar", `"a"`, `"b"`, []string{`$: "a" -> "b"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONDiff(tt.left, tt.right)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONDiff(%s, %s) =\n%s\nwant\n%s", tt.left, tt.right, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestJSONDiffInvalid(t *testing.T) {
+	if _, err := JSONDiff(`{"a":`, `{}`); err == nil || !strings.Contains(err.Error(), "left") {
+		t.Errorf("invalid left JSON: err = %v", err)
+	}
+	if _, err := JSONDiff(`{}`, `nope`); err == nil || !strings.Contains(err.Error(), "right") {
+		t.Errorf("invalid right JSON: err = %v", err)
+	}
+}